@@ -2,6 +2,7 @@ package auth0
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/go-zoox/oauth2"
 )
@@ -14,6 +15,11 @@ type Auth0Config struct {
 	Scope        string `json:"scope"`
 	//
 	BaseURL string `json:"base_url"`
+	//
+	Audience string   `json:"audience"`
+	Resource []string `json:"resource"`
+	//
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri"`
 }
 
 func New(cfg *Auth0Config) (oauth2.Client, error) {
@@ -27,11 +33,16 @@ func New(cfg *Auth0Config) (oauth2.Client, error) {
 		AuthURL:      fmt.Sprintf("%s/authorize", cfg.BaseURL),
 		TokenURL:     fmt.Sprintf("%s/oauth/token", cfg.BaseURL),
 		UserInfoURL:  fmt.Sprintf("%s/userinfo", cfg.BaseURL),
-		LogoutURL:    fmt.Sprintf("%s/logout", cfg.BaseURL),
+		LogoutURL:    fmt.Sprintf("%s/v2/logout", cfg.BaseURL),
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
+		Audience:     cfg.Audience,
+		Resource:     cfg.Resource,
+		Issuer:       fmt.Sprintf("%s/", cfg.BaseURL),
+		//
+		PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
 		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
@@ -45,5 +56,37 @@ func New(cfg *Auth0Config) (oauth2.Client, error) {
 		// HomepageAttributeName: "html_url",
 	}
 
+	// reference: https://auth0.com/docs/api/authentication#logout
+	config.GetEndSessionURL = func(cfg *oauth2.Config, req *oauth2.LogoutRequest) string {
+		query := url.Values{}
+		query.Set("client_id", cfg.ClientID)
+		if req.PostLogoutRedirectURI != "" {
+			query.Set("returnTo", req.PostLogoutRedirectURI)
+		}
+		return cfg.LogoutURL + "?" + query.Encode()
+	}
+
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "auth0",
+		DisplayName:    "Auth0",
+		Icon:           "auth0",
+		DefaultScopes:  []string{"profile", "email", "openid"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI", "BaseURL"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		return New(&Auth0Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+			BaseURL:      cfg.BaseURL,
+			Audience:     cfg.Audience,
+			Resource:     cfg.Resource,
+			//
+			PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
+		})
+	})
+}
@@ -0,0 +1,36 @@
+package oauth2
+
+import (
+	"encoding/base64"
+	"net/url"
+)
+
+// AuthStyle is how the client authenticates at the token endpoint, named by token_endpoint_auth_method.
+const (
+	// AuthStyleInParams sends client_id and client_secret in the request body, which is the default.
+	AuthStyleInParams = "client_secret_post"
+	// AuthStyleInHeader sends client_id and client_secret with HTTP Basic authentication.
+	AuthStyleInHeader = "client_secret_basic"
+	// AuthStyleNone sends client_id only, used by public clients.
+	AuthStyleNone = "none"
+)
+
+// applyClientAuth adds the client authentication to the token request by AuthStyle,
+// and returns the extra headers.
+func (oac *Config) applyClientAuth(form url.Values) map[string]string {
+	switch oac.AuthStyle {
+	case AuthStyleInHeader:
+		// RFC 6749 2.3.1, client id and secret are form encoded before base64
+		credentials := url.QueryEscape(oac.ClientID) + ":" + url.QueryEscape(oac.ClientSecret)
+		return map[string]string{
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)),
+		}
+	case AuthStyleNone:
+		form.Set("client_id", oac.ClientID)
+		return nil
+	default:
+		form.Set("client_id", oac.ClientID)
+		form.Set("client_secret", oac.ClientSecret)
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AuthorizationDetail is the authorization details object of Rich Authorization Requests (RFC 9396).
+type AuthorizationDetail struct {
+	Type       string   `json:"type"`
+	Locations  []string `json:"locations,omitempty"`
+	Actions    []string `json:"actions,omitempty"`
+	DataTypes  []string `json:"datatypes,omitempty"`
+	Identifier string   `json:"identifier,omitempty"`
+	Privileges []string `json:"privileges,omitempty"`
+
+	// Fields are the type specific fields, such as instructedAmount of payment_initiation.
+	Fields map[string]any `json:"-"`
+}
+
+// authorizationDetail is used to avoid recursion in MarshalJSON and UnmarshalJSON.
+type authorizationDetail AuthorizationDetail
+
+// MarshalJSON encodes the detail with the type specific fields inlined.
+func (d AuthorizationDetail) MarshalJSON() ([]byte, error) {
+	common, err := json.Marshal(authorizationDetail(d))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.Fields) == 0 {
+		return common, nil
+	}
+
+	merged := map[string]any{}
+	for k, v := range d.Fields {
+		merged[k] = v
+	}
+	if err := json.Unmarshal(common, &merged); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON decodes the detail, the unknown members are kept in Fields.
+func (d *AuthorizationDetail) UnmarshalJSON(data []byte) error {
+	var common authorizationDetail
+	if err := json.Unmarshal(data, &common); err != nil {
+		return err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "locations", "actions", "datatypes", "identifier", "privileges"} {
+		delete(fields, key)
+	}
+	if len(fields) != 0 {
+		common.Fields = fields
+	}
+
+	*d = AuthorizationDetail(common)
+	return nil
+}
+
+// encodeAuthorizationDetails encodes the authorization_details request parameter.
+func encodeAuthorizationDetails(details []AuthorizationDetail) (string, error) {
+	for i, detail := range details {
+		if detail.Type == "" {
+			return "", fmt.Errorf("oauth2: authorization_details[%d] type is required", i)
+		}
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return "", fmt.Errorf("oauth2: failed to encode authorization_details: %s", err)
+	}
+
+	return string(data), nil
+}
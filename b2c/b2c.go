@@ -0,0 +1,230 @@
+package b2c
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-zoox/oauth2"
+)
+
+// DefaultPolicy is the default sign-up and sign-in user flow.
+const DefaultPolicy = "B2C_1_signupsignin"
+
+// Error codes of Azure AD B2C in error_description.
+const (
+	// ErrorCodePasswordReset is returned by the sign-in policy when the user clicks forgot password.
+	ErrorCodePasswordReset = "AADB2C90118"
+	// ErrorCodeCancelled is returned when the user cancels the password reset or profile edit.
+	ErrorCodeCancelled = "AADB2C90091"
+)
+
+type B2CConfig struct {
+	// config.Config
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RedirectURI  string `json:"redirect_uri"`
+	Scope        string `json:"scope"`
+	//
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri"`
+	//
+	// Tenant is the tenant name, such as contoso or contoso.onmicrosoft.com.
+	Tenant string `json:"tenant"`
+	// Domain is the custom domain, such as login.contoso.com, default: {tenant}.b2clogin.com.
+	Domain string `json:"domain"`
+	// Policy is the sign-up and sign-in user flow, default: DefaultPolicy.
+	Policy string `json:"policy"`
+	// PasswordResetPolicy is the password reset user flow, such as B2C_1_passwordreset.
+	PasswordResetPolicy string `json:"password_reset_policy"`
+	// ProfileEditPolicy is the profile editing user flow, such as B2C_1_profileedit.
+	ProfileEditPolicy string `json:"profile_edit_policy"`
+}
+
+// Client is the Azure AD B2C client, which is the client of the sign-up and sign-in policy,
+// with the password reset and profile edit user flows.
+type Client struct {
+	oauth2.Client
+	//
+	resetPassword oauth2.Client
+	editProfile   oauth2.Client
+	// states is the policy clients of the in-flight password reset and profile edit by state.
+	states sync.Map
+}
+
+type policyState struct {
+	client    oauth2.Client
+	expiresAt time.Time
+}
+
+// New creates the Azure AD B2C client, which is a *Client.
+func New(cfg *B2CConfig) (oauth2.Client, error) {
+	tenant := strings.TrimSuffix(cfg.Tenant, ".onmicrosoft.com")
+	if tenant == "" {
+		return nil, fmt.Errorf("oauth2: b2c tenant is required")
+	}
+
+	policy := cfg.Policy
+	if policy == "" {
+		policy = DefaultPolicy
+	}
+
+	client := &Client{}
+
+	var err error
+	if client.Client, err = oauth2.New(newPolicyConfig(cfg, tenant, policy)); err != nil {
+		return nil, err
+	}
+
+	if cfg.PasswordResetPolicy != "" {
+		if client.resetPassword, err = oauth2.New(newPolicyConfig(cfg, tenant, cfg.PasswordResetPolicy)); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ProfileEditPolicy != "" {
+		if client.editProfile, err = oauth2.New(newPolicyConfig(cfg, tenant, cfg.ProfileEditPolicy)); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// newPolicyConfig creates the config of the user flow, the user is from the verified id_token,
+// since user flows have no userinfo endpoint.
+func newPolicyConfig(cfg *B2CConfig, tenant, policy string) oauth2.Config {
+	domain := cfg.Domain
+	if domain == "" {
+		domain = tenant + ".b2clogin.com"
+	}
+
+	scope := cfg.Scope
+	if scope == "" {
+		scope = "openid offline_access"
+	}
+
+	baseURL := fmt.Sprintf("https://%s/%s.onmicrosoft.com/%s", domain, tenant, policy)
+
+	return oauth2.Config{
+		Name:         "Azure AD B2C",
+		AuthURL:      baseURL + "/oauth2/v2.0/authorize",
+		TokenURL:     baseURL + "/oauth2/v2.0/token",
+		LogoutURL:    baseURL + "/oauth2/v2.0/logout",
+		BaseURL:      baseURL,
+		Scope:        scope,
+		RedirectURI:  cfg.RedirectURI,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		//
+		PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
+		//
+		IDAttributeName:       "oid",
+		NicknameAttributeName: "name",
+		//
+		ClaimMappings: map[string]*oauth2.ClaimMapping{
+			"email": {Paths: []string{"emails.0", "email"}},
+		},
+		//
+		// user flows have no userinfo endpoint, the user is from the id_token
+		GetUserResponse: getUserResponse,
+	}
+}
+
+// ResetPassword starts the password reset user flow.
+func (c *Client) ResetPassword(state string, callback func(loginUrl string), options ...oauth2.Option) error {
+	return c.authorize(c.resetPassword, "password reset", state, callback, options...)
+}
+
+// EditProfile starts the profile edit user flow.
+func (c *Client) EditProfile(state string, callback func(loginUrl string), options ...oauth2.Option) error {
+	return c.authorize(c.editProfile, "profile edit", state, callback, options...)
+}
+
+// HandleAuthorizeError handles the error_description of the authorize callback:
+// AADB2C90118 starts the password reset, and AADB2C90091 (cancelled) goes back to sign-in,
+// it returns false for other errors.
+func (c *Client) HandleAuthorizeError(state, errorDescription string, callback func(loginUrl string), options ...oauth2.Option) bool {
+	switch {
+	case strings.HasPrefix(errorDescription, ErrorCodePasswordReset) && c.resetPassword != nil:
+		return c.ResetPassword(state, callback, options...) == nil
+	case strings.HasPrefix(errorDescription, ErrorCodeCancelled):
+		c.Authorize(state, callback, options...)
+		return true
+	default:
+		return false
+	}
+}
+
+// Callback redeems the code with the user flow the state started with.
+func (c *Client) Callback(code, state string, cb func(user *oauth2.User, token *oauth2.Token, err error), options ...oauth2.Option) {
+	if value, ok := c.states.LoadAndDelete(state); ok {
+		if ps := value.(*policyState); time.Now().Before(ps.expiresAt) {
+			ps.client.Callback(code, state, cb, options...)
+			return
+		}
+	}
+
+	c.Client.Callback(code, state, cb, options...)
+}
+
+// Update swaps the config of the sign-up and sign-in policy,
+// and the credentials of the other policies.
+func (c *Client) Update(config oauth2.Config) error {
+	for _, client := range []oauth2.Client{c.resetPassword, c.editProfile} {
+		if client == nil {
+			continue
+		}
+
+		if err := client.Update(oauth2.MergeCredentials(client.Config(), &config)); err != nil {
+			return err
+		}
+	}
+
+	return c.Client.Update(config)
+}
+
+func (c *Client) authorize(client oauth2.Client, flow, state string, callback func(loginUrl string), options ...oauth2.Option) error {
+	if client == nil {
+		return fmt.Errorf("oauth2: b2c %s policy is not configured", flow)
+	}
+
+	now := time.Now()
+	c.states.Range(func(key, value any) bool {
+		if now.After(value.(*policyState).expiresAt) {
+			c.states.Delete(key)
+		}
+		return true
+	})
+
+	c.states.Store(state, &policyState{
+		client:    client,
+		expiresAt: now.Add(oauth2.LoginStateTTL),
+	})
+
+	client.Authorize(state, callback, options...)
+	return nil
+}
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "b2c",
+		DisplayName:    "Azure AD B2C",
+		Icon:           "microsoft",
+		DefaultScopes:  []string{"openid", "offline_access"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI", "ProviderOptions"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		// tenant (required), domain, policy, password_reset_policy and profile_edit_policy
+		config := &B2CConfig{}
+		if err := oauth2.DecodeProviderOptions(cfg, config); err != nil {
+			return nil, err
+		}
+
+		config.ClientID = cfg.ClientID
+		config.ClientSecret = cfg.ClientSecret
+		config.RedirectURI = cfg.RedirectURI
+		config.Scope = cfg.Scope
+		config.PostLogoutRedirectURI = cfg.PostLogoutRedirectURI
+		return New(config)
+	})
+}
@@ -0,0 +1,77 @@
+package b2c
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-zoox/core-utils/safe"
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2"
+)
+
+// metadata caches the discovery documents by policy base url,
+// oauth2.Discover is not used since the issuer of B2C is not the policy url.
+var metadata = safe.NewMap[string, *oauth2.ProviderMetadata]()
+
+// getUserResponse returns the claims of the verified id_token as the user info.
+func getUserResponse(cfg *oauth2.Config, token *oauth2.Token, code string) (*fetch.Response, error) {
+	if token.IDToken == "" {
+		return nil, oauth2.ErrIDTokenEmpty
+	}
+
+	m, err := discover(cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet, err := m.KeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := &oauth2.IDTokenVerifier{
+		Issuers:  []string{m.Issuer},
+		ClientID: cfg.ClientID,
+		KeySet:   keySet,
+		Nonce:    token.Nonce(),
+	}
+
+	claims, err := verifier.Verify(token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetch.Response{
+		Status: 200,
+		Body:   body,
+	}, nil
+}
+
+func discover(baseURL string) (*oauth2.ProviderMetadata, error) {
+	if metadata.Has(baseURL) {
+		return metadata.Get(baseURL), nil
+	}
+
+	response, err := fetch.Get(baseURL + "/v2.0/.well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to discover b2c policy(%s): %s", baseURL, err)
+	}
+
+	if response.Status != 200 {
+		return nil, fmt.Errorf("oauth2: failed to discover b2c policy(%s): (status: %d) %s", baseURL, response.Status, strings.TrimSpace(response.String()))
+	}
+
+	m := &oauth2.ProviderMetadata{}
+	if err := response.UnmarshalJSON(m); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid discovery document of b2c policy(%s): %s", baseURL, err)
+	}
+
+	metadata.Set(baseURL, m)
+	return m, nil
+}
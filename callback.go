@@ -7,12 +7,12 @@ type StepCallback struct {
 }
 
 // GetToken gets the token by code and state.
-func (oa *StepCallback) GetToken(config *Config, code, state string) (*Token, error) {
+func (oa *StepCallback) GetToken(config *Config, code, state string, options ...Option) (*Token, error) {
 	if len(code) == 0 || len(state) == 0 {
 		return nil, errors.New("invalid oauth2 login callback, code or state are required")
 	}
 
-	token, err := GetToken(config, code, state)
+	token, err := GetToken(config, code, state, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,306 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-zoox/fetch"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// Claim transform types.
+const (
+	// ClaimTransformLower lowercases the values.
+	ClaimTransformLower = "lower"
+	// ClaimTransformUpper uppercases the values.
+	ClaimTransformUpper = "upper"
+	// ClaimTransformTrim trims the spaces of the values.
+	ClaimTransformTrim = "trim"
+	// ClaimTransformSplit splits the values by Value, default: ",".
+	ClaimTransformSplit = "split"
+	// ClaimTransformJoin joins the values into one by Value, default: ",".
+	ClaimTransformJoin = "join"
+	// ClaimTransformPrefix prepends Value to the values.
+	ClaimTransformPrefix = "prefix"
+	// ClaimTransformSuffix appends Value to the values.
+	ClaimTransformSuffix = "suffix"
+	// ClaimTransformReplace replaces the matches of the regular expression Pattern with Value,
+	// which supports $1 for submatches.
+	ClaimTransformReplace = "replace"
+	// ClaimTransformDefault uses Value if there is no value.
+	ClaimTransformDefault = "default"
+)
+
+// ClaimMapping maps a User field from the user info response.
+//
+// Paths are gjson paths tried in order until one has a value,
+// such as emails.#(verified==true).email, or templates of paths,
+// such as "{{given_name}} {{family_name}}". The transforms are applied in order.
+//
+// In JSON and YAML, a mapping can also be a path or a list of paths.
+type ClaimMapping struct {
+	Paths      []string         `json:"paths" yaml:"paths"`
+	Transforms []ClaimTransform `json:"transforms,omitempty" yaml:"transforms,omitempty"`
+}
+
+// ClaimTransform is a transform of the claim values.
+type ClaimTransform struct {
+	Type    string `json:"type" yaml:"type"`
+	Value   string `json:"value,omitempty" yaml:"value,omitempty"`
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// claimFields are the User fields which can be mapped by claim mappings.
+var claimFields = map[string]func(user *User, values []string){
+	"id":             func(user *User, values []string) { user.ID = first(values) },
+	"username":       func(user *User, values []string) { user.Username = first(values) },
+	"email":          func(user *User, values []string) { user.Email = first(values) },
+	"avatar":         func(user *User, values []string) { user.Avatar = first(values) },
+	"nickname":       func(user *User, values []string) { user.Nickname = first(values) },
+	"homepage":       func(user *User, values []string) { user.Homepage = first(values) },
+	"name":           func(user *User, values []string) { user.Name = first(values) },
+	"given_name":     func(user *User, values []string) { user.GivenName = first(values) },
+	"family_name":    func(user *User, values []string) { user.FamilyName = first(values) },
+	"middle_name":    func(user *User, values []string) { user.MiddleName = first(values) },
+	"profile":        func(user *User, values []string) { user.Profile = first(values) },
+	"gender":         func(user *User, values []string) { user.Gender = first(values) },
+	"birthdate":      func(user *User, values []string) { user.Birthdate = first(values) },
+	"zoneinfo":       func(user *User, values []string) { user.ZoneInfo = first(values) },
+	"locale":         func(user *User, values []string) { user.Locale = first(values) },
+	"phone":          func(user *User, values []string) { user.Phone = first(values) },
+	"email_verified": func(user *User, values []string) { user.EmailVerified = gjson.Parse(first(values)).Bool() },
+	"phone_verified": func(user *User, values []string) { user.PhoneVerified = gjson.Parse(first(values)).Bool() },
+	"groups":         func(user *User, values []string) { user.Groups = values },
+	"permissions":    func(user *User, values []string) { user.Permissions = values },
+}
+
+var claimTemplate = regexp.MustCompile(`{{\s*([^}\s]+)\s*}}`)
+
+// UnmarshalJSON unmarshals the mapping, or the shorthand path and list of paths.
+func (m *ClaimMapping) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		m.Paths = []string{path}
+		return nil
+	}
+
+	var paths []string
+	if err := json.Unmarshal(data, &paths); err == nil {
+		m.Paths = paths
+		return nil
+	}
+
+	type mapping ClaimMapping
+	return json.Unmarshal(data, (*mapping)(m))
+}
+
+// UnmarshalYAML unmarshals the mapping, or the shorthand path and list of paths.
+func (m *ClaimMapping) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		m.Paths = []string{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		return node.Decode(&m.Paths)
+	default:
+		type mapping ClaimMapping
+		return node.Decode((*mapping)(m))
+	}
+}
+
+// Values evaluates the mapping on the response.
+func (m *ClaimMapping) Values(response *fetch.Response) []string {
+	values := []string{}
+	for _, path := range m.Paths {
+		values = claimValues(response, path)
+		if len(values) != 0 {
+			break
+		}
+	}
+
+	for _, transform := range m.Transforms {
+		values = transform.apply(values)
+	}
+
+	return values
+}
+
+// claimValues gets the non-empty values of the path or template.
+func claimValues(response *fetch.Response, path string) []string {
+	if strings.Contains(path, "{{") {
+		found := false
+		value := claimTemplate.ReplaceAllStringFunc(path, func(placeholder string) string {
+			v := response.Get(claimTemplate.FindStringSubmatch(placeholder)[1]).String()
+			found = found || v != ""
+			return v
+		})
+		if value = strings.TrimSpace(value); !found || value == "" {
+			return nil
+		}
+
+		return []string{value}
+	}
+
+	values := []string{}
+	result := response.Get(path)
+	if result.IsArray() {
+		result.ForEach(func(key, value gjson.Result) bool {
+			if v := value.String(); v != "" {
+				values = append(values, v)
+			}
+			return true
+		})
+	} else if v := result.String(); v != "" {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+func (t *ClaimTransform) apply(values []string) []string {
+	separator := t.Value
+	if separator == "" {
+		separator = ","
+	}
+
+	transformed := []string{}
+	switch t.Type {
+	case ClaimTransformSplit:
+		for _, value := range values {
+			for _, part := range strings.Split(value, separator) {
+				if part = strings.TrimSpace(part); part != "" {
+					transformed = append(transformed, part)
+				}
+			}
+		}
+	case ClaimTransformJoin:
+		if len(values) != 0 {
+			transformed = append(transformed, strings.Join(values, separator))
+		}
+	case ClaimTransformDefault:
+		if len(values) == 0 {
+			return []string{t.Value}
+		}
+		return values
+	default:
+		var re *regexp.Regexp
+		if t.Type == ClaimTransformReplace {
+			var err error
+			if re, err = regexp.Compile(t.Pattern); err != nil {
+				return values
+			}
+		}
+
+		for _, value := range values {
+			switch t.Type {
+			case ClaimTransformLower:
+				value = strings.ToLower(value)
+			case ClaimTransformUpper:
+				value = strings.ToUpper(value)
+			case ClaimTransformTrim:
+				value = strings.TrimSpace(value)
+			case ClaimTransformPrefix:
+				value = t.Value + value
+			case ClaimTransformSuffix:
+				value = value + t.Value
+			case ClaimTransformReplace:
+				value = re.ReplaceAllString(value, t.Value)
+			}
+			transformed = append(transformed, value)
+		}
+	}
+
+	return transformed
+}
+
+// validate validates the transform type and pattern.
+func (t *ClaimTransform) validate() error {
+	switch t.Type {
+	case ClaimTransformLower, ClaimTransformUpper, ClaimTransformTrim, ClaimTransformSplit, ClaimTransformJoin,
+		ClaimTransformPrefix, ClaimTransformSuffix, ClaimTransformDefault:
+		return nil
+	case ClaimTransformReplace:
+		if _, err := regexp.Compile(t.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern(%s): %s", t.Pattern, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown transform %s", t.Type)
+	}
+}
+
+// validateClaimMappings validates the claim mappings in order, name is the owner in error messages.
+func validateClaimMappings(name string, mappings map[string]*ClaimMapping) error {
+	fields := make([]string, 0, len(mappings))
+	for field := range mappings {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	errs := Errors{}
+	for _, field := range fields {
+		mapping := mappings[field]
+		if _, ok := claimFields[field]; !ok {
+			errs.Add(fmt.Errorf("oauth2: %s claim mapping %s is not a user field", name, field))
+			continue
+		}
+
+		if mapping == nil || len(mapping.Paths) == 0 {
+			errs.Add(fmt.Errorf("oauth2: %s claim mapping %s has no path", name, field))
+			continue
+		}
+
+		for _, path := range mapping.Paths {
+			if strings.TrimSpace(path) == "" {
+				errs.Add(fmt.Errorf("oauth2: %s claim mapping %s has an empty path", name, field))
+			}
+		}
+
+		for _, transform := range mapping.Transforms {
+			if err := transform.validate(); err != nil {
+				errs.Add(fmt.Errorf("oauth2: %s claim mapping %s %s", name, field, err))
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// applyClaimMappings sets the mapped fields of the user, the fields without values are kept.
+func applyClaimMappings(user *User, response *fetch.Response, mappings map[string]*ClaimMapping) {
+	for field, mapping := range mappings {
+		set, ok := claimFields[field]
+		if !ok || mapping == nil {
+			continue
+		}
+
+		if values := mapping.Values(response); len(values) != 0 {
+			set(user, values)
+		}
+	}
+}
+
+// mergeClaimMappings returns the mappings of base overridden by the mappings of override.
+func mergeClaimMappings(base, override map[string]*ClaimMapping) map[string]*ClaimMapping {
+	mappings := map[string]*ClaimMapping{}
+	for field, mapping := range base {
+		mappings[field] = mapping
+	}
+	for field, mapping := range override {
+		mappings[field] = mapping
+	}
+
+	return mappings
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
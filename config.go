@@ -3,6 +3,7 @@ package oauth2
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -19,14 +20,32 @@ type Config struct {
 	RefershTokenURL string
 	//
 	LogoutURL string
+	// PostLogoutRedirectURI is where the provider redirects to after logout.
+	PostLogoutRedirectURI string
 	//
 	RegisterURL string
 	// callback url = server url + callback path, example: https://example.com/login/callback
 	RedirectURI string
 	Scope       string
+	// ScopeSeparator is the separator of scopes in authorize request, default: space
+	ScopeSeparator string
+	// IncludeGrantedScopes means the provider supports include_granted_scopes (Google),
+	// which is used when upgrading scopes.
+	IncludeGrantedScopes bool
 	//
 	ClientID     string
 	ClientSecret string
+	// AuthStyle is how the client authenticates at the token endpoint, default: AuthStyleInParams
+	AuthStyle string
+
+	// Resource is the resource indicators (RFC 8707) sent in authorize, token and refresh requests.
+	Resource []string
+	// Audience is the audience parameter (Auth0) sent in authorize, token and refresh requests.
+	Audience string
+	// AuthorizationDetails is the authorization_details (RFC 9396) sent in authorize request.
+	AuthorizationDetails []AuthorizationDetail
+	// AuthParams are the extra parameters always sent in authorize request, such as prompt=consent.
+	AuthParams map[string]string
 
 	//
 	ClientIDAttributeName     string
@@ -42,8 +61,12 @@ type Config struct {
 	RefreshTokenAttributeName string
 	// Token.expires_in, default: expires_in
 	ExpiresInAttributeName string
-	// Token.id_token, default: id_token
+	// Token.token_type, default: token_type
 	TokenTypeAttributeName string
+	// Token.id_token, default: id_token
+	IDTokenAttributeName string
+	// Token.scopes, default: scope
+	GrantedScopeAttributeName string
 
 	// User.username, default: username
 	UsernameAttributeName string
@@ -61,82 +84,114 @@ type Config struct {
 	PermissionsAttributeName string
 	// User.groups, default: groups
 	GroupsAttributeName string
+	// User.name, default: name
+	NameAttributeName string
+	// User.given_name, default: given_name
+	GivenNameAttributeName string
+	// User.family_name, default: family_name
+	FamilyNameAttributeName string
+	// User.middle_name, default: middle_name
+	MiddleNameAttributeName string
+	// User.profile, default: profile
+	ProfileAttributeName string
+	// User.email_verified, default: email_verified
+	EmailVerifiedAttributeName string
+	// User.gender, default: gender
+	GenderAttributeName string
+	// User.birthdate, default: birthdate
+	BirthdateAttributeName string
+	// User.zoneinfo, default: zoneinfo
+	ZoneInfoAttributeName string
+	// User.locale, default: locale
+	LocaleAttributeName string
+	// User.phone, default: phone_number
+	PhoneAttributeName string
+	// User.phone_verified, default: phone_number_verified
+	PhoneVerifiedAttributeName string
+	// User.address, default: address
+	AddressAttributeName string
+	// User.updated_at, default: updated_at
+	UpdatedAtAttributeName string
+	// ClaimMappings maps the User fields by fallback paths and transforms, keyed by the json name of User field,
+	// which take precedence over the attribute names.
+	ClaimMappings map[string]*ClaimMapping
+	// RoleMapping maps the groups and claims of the user to User.Roles.
+	RoleMapping *RoleMapping
 
 	// url: login(authorize) + logout
-	GetLoginURL    func(cfg *Config, state string) string
+	GetLoginURL func(cfg *Config, state string) string
+	// GetLogoutURL is the legacy logout url hook, which cannot see the logout request,
+	// use GetEndSessionURL instead.
 	GetLogoutURL   func(cfg *Config) string
 	GetRegisterURL func(cfg *Config) string
+	// GetEndSessionURL builds the logout url from the logout request.
+	GetEndSessionURL func(cfg *Config, req *LogoutRequest) string
 
 	// token
 	GetAccessTokenResponse func(cfg *Config, code string, state string) (*fetch.Response, error)
 	// user
 	GetUserResponse func(cfg *Config, token *Token, code string) (*fetch.Response, error)
+	// EnrichUser completes the user with other apis, such as emails and teams, before the role mapping.
+	EnrichUser func(cfg *Config, token *Token, user *User) error
 	//
 	RefreshToken func(cfg *Config, refreshToken string) (*fetch.Response, error)
 
 	// base url for identity providers, such as auth0, authing
 	BaseURL string
+	// APIBaseURL is the api base url of self-hosted providers, such as https://github.example.com/api/v3.
+	APIBaseURL string
+	// Issuer is the OpenID Connect issuer, used for discovery.
+	Issuer string
+	// Version is the provider api version, such as doreamon v2.
+	Version string
+	// HTTPClient sends the token and refresh requests, default: a client with the timeout of fetch.Timeout.
+	HTTPClient *http.Client
+	// ProviderOptions are the provider specific options of the provider factories, such as fetch_teams of GitHub,
+	// see DecodeProviderOptions.
+	ProviderOptions map[string]any
 }
 
 // generateLoginURL gets the authorize url.
 //
 // Example: https://login.example.com/authorize?client_id=CLIENT_ID&redirect_uri=https%3A%2F%2Fabc.com%2Flogin%2Fcallback&response_type=code&scope=openid&state=anything
-func (oac *Config) generateLoginURL(state string) string {
+func (oac *Config) generateLoginURL(state string, opts *Options) (string, error) {
 	if state == "" {
 		state = "anything"
 	}
 
-	if oac.GetLoginURL != nil {
-		return oac.GetLoginURL(oac, state)
-	}
-
-	clientID := oac.ClientID
-	redirectURI := oac.RedirectURI // oac.ServerUrl + "/login/callback"
-	responseType := "code"
-	scope := oac.Scope
-
-	if scope == "" {
-		scope = "openid"
+	extra, err := oac.authorizeParams(opts)
+	if err != nil {
+		return "", err
 	}
 
-	return strings.Join([]string{
-		oac.AuthURL,
-		fmt.Sprintf("?%s=", oac.ClientIDAttributeName), clientID,
-		fmt.Sprintf("&%s=", oac.RedirectURIAttributeName), url.QueryEscape(redirectURI),
-		fmt.Sprintf("&%s=", oac.ResponseTypeAttributeName), responseType,
-		fmt.Sprintf("&%s=", oac.ScopeAttributeName), url.QueryEscape(scope),
-		fmt.Sprintf("&%s=", oac.StateAttributeName), url.QueryEscape(state),
-	}, "")
-}
-
-// generateLogoutURL gets the logout url.
-//
-// Example: https://login.example.com/logout?client_id=CLIENT_ID&redirect_uri=https%3A%2F%2Fabc.com%2Flogin/callback
-func (oac *Config) generateLogoutURL(state string) string {
-	if oac.GetLogoutURL != nil {
-		return oac.GetLogoutURL(oac)
+	if oac.GetLoginURL != nil {
+		return appendQuery(oac.GetLoginURL(oac, state), extra), nil
 	}
 
-	clientID := oac.ClientID
-	redirectURI := oac.RedirectURI // oac.ServerUrl + "/login/callback"
-	responseType := "code"
 	scope := oac.Scope
+	if len(opts.Scopes) != 0 {
+		scope = strings.Join(opts.Scopes, oac.scopeSeparator())
+	}
 	if scope == "" {
 		scope = "openid"
 	}
 
-	if oac.LogoutURL == "" {
-		return ""
+	params := url.Values{}
+	params.Set(oac.ClientIDAttributeName, oac.ClientID)
+	params.Set(oac.RedirectURIAttributeName, oac.RedirectURI) // oac.ServerUrl + "/login/callback"
+	params.Set(oac.ResponseTypeAttributeName, "code")
+	params.Set(oac.ScopeAttributeName, scope)
+	params.Set(oac.StateAttributeName, state)
+	for key, values := range extra {
+		params[key] = values
 	}
 
-	return strings.Join([]string{
-		oac.LogoutURL,
-		fmt.Sprintf("?%s=", oac.ClientIDAttributeName), clientID,
-		fmt.Sprintf("&%s=", oac.RedirectURIAttributeName), url.QueryEscape(redirectURI),
-		fmt.Sprintf("&%s=", oac.ResponseTypeAttributeName), responseType,
-		fmt.Sprintf("&%s=", oac.ScopeAttributeName), url.QueryEscape(scope),
-		fmt.Sprintf("&%s=", oac.StateAttributeName), url.QueryEscape(state),
-	}, "")
+	return appendQuery(oac.AuthURL, params), nil
+}
+
+// errorURL is the url of the error page, which is used when the url cannot be generated.
+func errorURL(message string) string {
+	return fmt.Sprintf("/error?code=%d&message=%s", 500, url.QueryEscape(message))
 }
 
 // generateRegisterURL gets the register url.
@@ -149,8 +204,7 @@ func (oac *Config) generateRegisterURL() string {
 
 	// @TODO
 	if oac.RegisterURL == "" {
-		message := url.QueryEscape(fmt.Sprintf("oauth2 %s does not support register", oac.Name))
-		return fmt.Sprintf("/error?code=%d&message=%s", 500, message)
+		return errorURL(fmt.Sprintf("oauth2 %s does not support register", oac.Name))
 	}
 
 	return strings.Join([]string{
@@ -201,6 +255,14 @@ func ApplyDefaultConfig(config *Config) (err error) {
 		config.TokenTypeAttributeName = "token_type"
 	}
 
+	if config.IDTokenAttributeName == "" {
+		config.IDTokenAttributeName = "id_token"
+	}
+
+	if config.GrantedScopeAttributeName == "" {
+		config.GrantedScopeAttributeName = "scope"
+	}
+
 	if config.UsernameAttributeName == "" {
 		config.UsernameAttributeName = "username"
 	}
@@ -233,36 +295,68 @@ func ApplyDefaultConfig(config *Config) (err error) {
 		config.GroupsAttributeName = "groups"
 	}
 
-	return
-}
+	if config.NameAttributeName == "" {
+		config.NameAttributeName = "name"
+	}
 
-// ValidateConfig validates the config.
-func ValidateConfig(config *Config) error {
-	if config.AuthURL == "" {
-		panic(ErrConfigAuthURLEmpty)
+	if config.GivenNameAttributeName == "" {
+		config.GivenNameAttributeName = "given_name"
+	}
+
+	if config.FamilyNameAttributeName == "" {
+		config.FamilyNameAttributeName = "family_name"
+	}
+
+	if config.MiddleNameAttributeName == "" {
+		config.MiddleNameAttributeName = "middle_name"
+	}
+
+	if config.ProfileAttributeName == "" {
+		config.ProfileAttributeName = "profile"
+	}
+
+	if config.EmailVerifiedAttributeName == "" {
+		config.EmailVerifiedAttributeName = "email_verified"
 	}
 
-	if config.TokenURL == "" {
-		panic(ErrConfigTokenURLEmpty)
+	if config.GenderAttributeName == "" {
+		config.GenderAttributeName = "gender"
 	}
 
-	if config.UserInfoURL == "" {
-		panic(ErrConfigUserInfoURLEmpty)
+	if config.BirthdateAttributeName == "" {
+		config.BirthdateAttributeName = "birthdate"
 	}
 
-	if config.RedirectURI == "" {
-		panic(ErrConfigRedirectURIEmpty)
+	if config.ZoneInfoAttributeName == "" {
+		config.ZoneInfoAttributeName = "zoneinfo"
 	}
 
-	if config.ClientID == "" {
-		panic(ErrConfigClientIDEmpty)
+	if config.LocaleAttributeName == "" {
+		config.LocaleAttributeName = "locale"
 	}
 
-	if config.ClientSecret == "" {
-		panic(ErrConfigClientSecretEmpty)
+	if config.PhoneAttributeName == "" {
+		config.PhoneAttributeName = "phone_number"
 	}
 
-	return nil
+	if config.PhoneVerifiedAttributeName == "" {
+		config.PhoneVerifiedAttributeName = "phone_number_verified"
+	}
+
+	if config.AddressAttributeName == "" {
+		config.AddressAttributeName = "address"
+	}
+
+	if config.UpdatedAtAttributeName == "" {
+		config.UpdatedAtAttributeName = "updated_at"
+	}
+
+	return
+}
+
+// ValidateConfig validates the config, all the problems are reported together as Errors.
+func ValidateConfig(config *Config) error {
+	return config.Validate()
 }
 
 // ErrConfigAuthURLEmpty is the error of AuthURL is empty.
@@ -283,4 +377,7 @@ var ErrConfigClientIDEmpty = errors.New("oauth2: config client id is empty")
 // ErrConfigClientSecretEmpty is the error of ClientSecret is empty.
 var ErrConfigClientSecretEmpty = errors.New("oauth2: config client secret is empty")
 
+// ErrConfigPublicClientSecret is the error of public client (AuthStyleNone) has a client secret.
+var ErrConfigPublicClientSecret = errors.New("oauth2: config client secret must be empty for public client (auth style none)")
+
 // var ErrConfigScopeEmpty = errors.New("oauth2: config scope is empty")
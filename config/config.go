@@ -1,9 +1,105 @@
 package config
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-zoox/oauth2"
+)
+
 // Config is the configuration export
 type Config struct {
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	RedirectURI  string `json:"redirect_uri"`
-	Scope        string `json:"scope"`
+	ClientID     string `json:"client_id" yaml:"client_id"`
+	ClientSecret string `json:"client_secret" yaml:"client_secret"`
+	RedirectURI  string `json:"redirect_uri" yaml:"redirect_uri"`
+	Scope        string `json:"scope" yaml:"scope"`
+	//
+	BaseURL               string `json:"base_url" yaml:"base_url"`
+	APIBaseURL            string `json:"api_base_url" yaml:"api_base_url"`
+	Issuer                string `json:"issuer" yaml:"issuer"`
+	Audience              string `json:"audience" yaml:"audience"`
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri" yaml:"post_logout_redirect_uri"`
+	Version               string `json:"version" yaml:"version"`
+	// PrivateKey is the PEM encoded private key of the jwt bearer grant, see JWTBearerConfig.
+	PrivateKey   string `json:"private_key" yaml:"private_key"`
+	PrivateKeyID string `json:"private_key_id" yaml:"private_key_id"`
+	// Claims are the claim mappings of the user fields, see oauth2.Config.ClaimMappings.
+	Claims map[string]*oauth2.ClaimMapping `json:"claims" yaml:"claims"`
+	// Roles maps the groups and claims of the user to application roles, see oauth2.Config.RoleMapping.
+	Roles *oauth2.RoleMapping `json:"roles" yaml:"roles"`
+	// Options are the provider specific options, such as fetch_teams of GitHub, see oauth2.Config.ProviderOptions.
+	Options map[string]any `json:"options" yaml:"options"`
+}
+
+// OAuth2Config converts to oauth2.Config, which is used by create.Create.
+func (c *Config) OAuth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:              c.ClientID,
+		ClientSecret:          c.ClientSecret,
+		RedirectURI:           c.RedirectURI,
+		Scope:                 c.Scope,
+		BaseURL:               c.BaseURL,
+		APIBaseURL:            c.APIBaseURL,
+		Issuer:                c.Issuer,
+		Audience:              c.Audience,
+		PostLogoutRedirectURI: c.PostLogoutRedirectURI,
+		Version:               c.Version,
+		ClaimMappings:         c.Claims,
+		RoleMapping:           c.Roles,
+		ProviderOptions:       c.Options,
+	}
+}
+
+// JWTBearerConfig converts to oauth2.JWTBearerConfig of the token endpoint,
+// which signs the assertion with PrivateKey and PrivateKeyID, the client id is the issuer.
+func (c *Config) JWTBearerConfig(tokenURL string) (*oauth2.JWTBearerConfig, error) {
+	if c.PrivateKey == "" {
+		return nil, fmt.Errorf("oauth2: private_key is required for the jwt bearer grant")
+	}
+
+	return &oauth2.JWTBearerConfig{
+		TokenURL:     tokenURL,
+		Issuer:       c.ClientID,
+		Audience:     c.Audience,
+		Scope:        c.Scope,
+		PrivateKey:   []byte(c.PrivateKey),
+		PrivateKeyID: c.PrivateKeyID,
+	}, nil
+}
+
+// Validate validates the required fields and reports all the problems together.
+func (c *Config) Validate(provider string) error {
+	errs := oauth2.Errors{}
+	if c.ClientID == "" {
+		errs.Add(fmt.Errorf("oauth2: provider(%s) client_id is required", provider))
+	}
+
+	if c.ClientSecret == "" && c.PrivateKey == "" {
+		errs.Add(fmt.Errorf("oauth2: provider(%s) client_secret is required", provider))
+	}
+
+	if c.RedirectURI == "" && c.PrivateKey == "" {
+		errs.Add(fmt.Errorf("oauth2: provider(%s) redirect_uri is required", provider))
+	}
+
+	return errs.Err()
+}
+
+// fields returns the settable string fields of the config by json name.
+func (c *Config) fields() map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+
+	value := reflect.ValueOf(c).Elem()
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || typ.Field(i).Type.Kind() != reflect.String {
+			continue
+		}
+
+		fields[name] = value.Field(i)
+	}
+
+	return fields
 }
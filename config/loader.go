@@ -0,0 +1,335 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-zoox/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix is the default prefix of environment overrides, such as OAUTH2_GITHUB_CLIENT_SECRET.
+const DefaultEnvPrefix = "OAUTH2"
+
+// Secret reference schemes, supported by client_secret and private_key.
+const (
+	// SecretFile reads the secret from file, such as file:///run/secrets/github.
+	SecretFile = "file://"
+	// SecretEnv reads the secret from environment, such as env://GITHUB_CLIENT_SECRET.
+	SecretEnv = "env://"
+)
+
+// Loader loads the configs of many providers from a file and environment overrides.
+type Loader struct {
+	// Path is the JSON (.json) or YAML (.yaml, .yml) file, optional.
+	Path string
+	// EnvPrefix is the prefix of environment overrides, empty means no prefix,
+	// Load and Watch use DefaultEnvPrefix.
+	EnvPrefix string
+	// Environ returns the environment, default: os.Environ.
+	Environ func() []string
+}
+
+// file is the config file, providers are keyed by name:
+//
+//	providers:
+//	  github:
+//	    client_id: xxx
+//	    client_secret: env://GITHUB_CLIENT_SECRET
+type file struct {
+	Providers map[string]*Config `json:"providers" yaml:"providers"`
+}
+
+// Load loads the configs of providers from the file and environment overrides.
+func Load(path string) (map[string]*Config, error) {
+	loader := &Loader{
+		Path:      path,
+		EnvPrefix: DefaultEnvPrefix,
+	}
+
+	return loader.Load()
+}
+
+// FromEnv loads the config of a single provider from environment,
+// such as GITHUB_CLIENT_ID when prefix is empty, or OAUTH2_GITHUB_CLIENT_ID when prefix is OAUTH2.
+func FromEnv(prefix string, provider string) (*Config, error) {
+	loader := &Loader{
+		EnvPrefix: prefix,
+	}
+
+	cfg := &Config{}
+	loader.applyEnv(provider, cfg)
+
+	errs := oauth2.Errors{}
+	errs.Add(resolveSecrets(provider, cfg))
+	errs.Add(cfg.Validate(provider))
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Load loads the configs, all the validation problems are reported together.
+func (l *Loader) Load() (map[string]*Config, error) {
+	configs := map[string]*Config{}
+	if l.Path != "" {
+		loaded, err := readFile(l.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, cfg := range loaded {
+			if cfg == nil {
+				cfg = &Config{}
+			}
+			configs[strings.ToLower(name)] = cfg
+		}
+	}
+
+	known := make([]string, 0, len(configs))
+	for provider := range configs {
+		known = append(known, provider)
+	}
+	for _, info := range oauth2.Providers() {
+		known = append(known, info.ID)
+	}
+
+	for _, provider := range l.envProviders(known) {
+		if _, ok := configs[provider]; !ok {
+			configs[provider] = &Config{}
+		}
+	}
+
+	providers := make([]string, 0, len(configs))
+	for provider := range configs {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	errs := oauth2.Errors{}
+	for _, provider := range providers {
+		cfg := configs[provider]
+		l.applyEnv(provider, cfg)
+		errs.Add(resolveSecrets(provider, cfg))
+		errs.Add(cfg.Validate(provider))
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (l *Loader) prefix() string {
+	if l.EnvPrefix == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(l.EnvPrefix, "_") + "_"
+}
+
+func (l *Loader) environ() map[string]string {
+	environ := os.Environ
+	if l.Environ != nil {
+		environ = l.Environ
+	}
+
+	env := map[string]string{}
+	for _, kv := range environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return env
+}
+
+// envKey returns the environment key of the field, such as OAUTH2_GITHUB_CLIENT_SECRET.
+func (l *Loader) envKey(provider, field string) string {
+	return l.prefix() + envName(provider) + "_" + strings.ToUpper(field)
+}
+
+// envName returns the provider name in environment keys, - and _ are the same, such as MY_IDP of my-idp.
+func envName(provider string) string {
+	return strings.ToUpper(strings.ReplaceAll(provider, "-", "_"))
+}
+
+// envProviders returns the providers which are configured by environment,
+// the known providers (in the file or registered) are matched by name first.
+func (l *Loader) envProviders(known []string) []string {
+	knownNames := map[string]string{}
+	for _, provider := range known {
+		knownNames[envName(provider)] = provider
+	}
+
+	fields := (&Config{}).fields()
+	providers := map[string]bool{}
+	for key := range l.environ() {
+		if !strings.HasPrefix(key, l.prefix()) {
+			continue
+		}
+
+		if provider := envProvider(strings.TrimPrefix(key, l.prefix()), fields, knownNames); provider != "" {
+			providers[provider] = true
+		}
+	}
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// envProvider returns the provider of the environment key without prefix, such as GITHUB_CLIENT_ID,
+// the longest field suffix wins, so GITHUB_POST_LOGOUT_REDIRECT_URI is not provider github_post_logout.
+func envProvider(key string, fields map[string]reflect.Value, known map[string]string) string {
+	provider, unknown := "", ""
+	matched, matchedUnknown := 0, 0
+	for field := range fields {
+		suffix := "_" + strings.ToUpper(field)
+		if !strings.HasSuffix(key, suffix) || len(key) == len(suffix) {
+			continue
+		}
+
+		name := strings.TrimSuffix(key, suffix)
+		if knownName, ok := known[name]; ok {
+			if len(suffix) > matched {
+				provider, matched = knownName, len(suffix)
+			}
+		} else if len(suffix) > matchedUnknown {
+			unknown, matchedUnknown = strings.ToLower(name), len(suffix)
+		}
+	}
+
+	if provider != "" {
+		return provider
+	}
+
+	return unknown
+}
+
+// applyEnv overrides the fields of cfg by the environment.
+func (l *Loader) applyEnv(provider string, cfg *Config) {
+	env := l.environ()
+	for field, value := range cfg.fields() {
+		if override, ok := env[l.envKey(provider, field)]; ok {
+			value.SetString(override)
+		}
+	}
+}
+
+// resolveSecrets resolves the file:// and env:// references of client_secret and private_key.
+func resolveSecrets(provider string, cfg *Config) error {
+	errs := oauth2.Errors{}
+	secrets := []struct {
+		name  string
+		value *string
+	}{
+		{"client_secret", &cfg.ClientSecret},
+		{"private_key", &cfg.PrivateKey},
+	}
+	for _, secret := range secrets {
+		resolved, err := ResolveSecret(*secret.value)
+		if err != nil {
+			errs.Add(fmt.Errorf("oauth2: provider(%s) %s: %s", provider, secret.name, err))
+			continue
+		}
+
+		*secret.value = resolved
+	}
+
+	return errs.Err()
+}
+
+// ResolveSecret resolves the secret reference, such as file:///run/secrets/github and env://GITHUB_SECRET,
+// other values are returned as is.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretFile):
+		path := strings.TrimPrefix(value, SecretFile)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file(%s): %s", path, err)
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, SecretEnv):
+		key := strings.TrimPrefix(value, SecretEnv)
+		secret, ok := os.LookupEnv(key)
+		if !ok {
+			return "", fmt.Errorf("secret env(%s) is not set", key)
+		}
+
+		return secret, nil
+	default:
+		return value, nil
+	}
+}
+
+func readFile(path string) (map[string]*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read config file(%s): %s", path, err)
+	}
+
+	unmarshal := yaml.Unmarshal
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		unmarshal = json.Unmarshal
+	case ".yaml", ".yml":
+	default:
+		return nil, fmt.Errorf("oauth2: unsupported config file(%s), only .json, .yaml and .yml are supported", path)
+	}
+
+	f := &file{}
+	if err := unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid config file(%s): %s", path, err)
+	}
+
+	return f.Providers, nil
+}
+
+// Source returns the loader as the config source of oauth2.Watcher,
+// the file and the secret references are read again on every reload.
+func (l *Loader) Source() oauth2.ConfigSource {
+	return oauth2.ConfigSourceFunc(func() (map[string]*oauth2.Config, error) {
+		loaded, err := l.Load()
+		if err != nil {
+			return nil, err
+		}
+
+		configs := map[string]*oauth2.Config{}
+		for provider, cfg := range loaded {
+			configs[provider] = cfg.OAuth2Config()
+		}
+
+		return configs, nil
+	})
+}
+
+// Watch reloads the provider credentials of the registry from the file and environment
+// every interval until the context is done, such as rotated client secrets.
+func Watch(ctx context.Context, path string, registry *oauth2.Registry, interval time.Duration) error {
+	loader := &Loader{
+		Path:      path,
+		EnvPrefix: DefaultEnvPrefix,
+	}
+
+	watcher := &oauth2.Watcher{
+		Source:   loader.Source(),
+		Registry: registry,
+		Interval: interval,
+	}
+
+	return watcher.Run(ctx)
+}
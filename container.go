@@ -1,40 +1,21 @@
 package oauth2
 
-import (
-	"fmt"
-
-	"github.com/go-zoox/core-utils/safe"
-)
-
-var clients = safe.NewMap[string, any]()
-
-// Register registers a new oauth2 service provider.
+// Register registers a new oauth2 service provider into the default registry.
 func Register(provider string, cfg *Config) error {
-	if provider == "" {
-		return fmt.Errorf("oauth2: provider is empty")
-	}
-
-	if clients.Has(provider) {
-		return fmt.Errorf("oauth2: provider(%s) already registered", provider)
-	}
+	return DefaultRegistry.Register(DefaultTenant, provider, cfg)
+}
 
-	if cfg.Name == "" {
-		cfg.Name = provider
-	}
+// Replace registers or replaces the oauth2 service provider in the default registry.
+func Replace(provider string, cfg *Config) error {
+	return DefaultRegistry.Replace(DefaultTenant, provider, cfg)
+}
 
-	clients.Set(provider, cfg)
-	return nil
+// Unregister removes the oauth2 service provider from the default registry.
+func Unregister(provider string) bool {
+	return DefaultRegistry.Remove(DefaultTenant, provider)
 }
 
 // Get gets the oauth2 service provider by name.
 func Get(provider string) (*Config, error) {
-	if provider == "" {
-		return nil, fmt.Errorf("oauth2: provider is empty")
-	}
-
-	if !clients.Has(provider) {
-		return nil, fmt.Errorf("oauth2: provider(%s) not registered", provider)
-	}
-
-	return clients.Get(provider).(*Config), nil
+	return DefaultRegistry.Get(DefaultTenant, provider)
 }
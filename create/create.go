@@ -4,95 +4,48 @@ import (
 	"fmt"
 
 	"github.com/go-zoox/oauth2"
-	"github.com/go-zoox/oauth2/auth0"
-	"github.com/go-zoox/oauth2/doreamon"
-	"github.com/go-zoox/oauth2/feishu"
-	"github.com/go-zoox/oauth2/github"
-	"github.com/go-zoox/oauth2/gitlab"
-	"github.com/go-zoox/oauth2/google"
-	"github.com/go-zoox/oauth2/kakao"
-	"github.com/go-zoox/oauth2/microsoft"
-	"github.com/go-zoox/oauth2/okta"
-	"github.com/go-zoox/oauth2/slack"
+
+	// register the builtin providers
+	_ "github.com/go-zoox/oauth2/auth0"
+	_ "github.com/go-zoox/oauth2/b2c"
+	_ "github.com/go-zoox/oauth2/dingtalk"
+	_ "github.com/go-zoox/oauth2/doreamon"
+	_ "github.com/go-zoox/oauth2/feishu"
+	_ "github.com/go-zoox/oauth2/github"
+	_ "github.com/go-zoox/oauth2/gitlab"
+	_ "github.com/go-zoox/oauth2/google"
+	_ "github.com/go-zoox/oauth2/kakao"
+	_ "github.com/go-zoox/oauth2/microsoft"
+	_ "github.com/go-zoox/oauth2/okta"
+	_ "github.com/go-zoox/oauth2/slack"
+	_ "github.com/go-zoox/oauth2/web3"
+	_ "github.com/go-zoox/oauth2/wechat"
+	_ "github.com/go-zoox/oauth2/weibo"
+	_ "github.com/go-zoox/oauth2/xiaomi"
 )
 
+// Create creates the client of the provider,
+// which is looked up in the provider registry (builtin providers, declarative definitions
+// and factories registered by oauth2.RegisterProvider), then the configs registered by oauth2.Register.
 func Create(provider string, cfg *oauth2.Config) (oauth2.Client, error) {
-	switch provider {
-	case "doreamon":
-		return doreamon.New(&doreamon.DoreamonConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-			Version:      "v2",
-		})
-	case "github":
-		return github.New(&github.GitHubConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "feishu":
-		return feishu.New(&feishu.FeishuConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "gitlab":
-		return gitlab.New(&gitlab.GitLabConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "slack":
-		return slack.New(&slack.SlackConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "kakao":
-		return kakao.New(&kakao.KakaoConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "google":
-		return google.New(&google.GoogleConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	case "microsoft":
-		return microsoft.New(&microsoft.MicrosoftConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-		})
-	//
-	case "auth0":
-		return auth0.New(&auth0.Auth0Config{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-			BaseURL:      cfg.BaseURL,
-		})
-	case "okta":
-		return okta.New(&okta.OktaConfig{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURI:  cfg.RedirectURI,
-			Scope:        cfg.Scope,
-			BaseURL:      cfg.BaseURL,
-		})
-	default:
+	if _, _, err := oauth2.LookupProvider(provider); err == nil {
+		return oauth2.CreateProvider(provider, cfg)
+	}
+
+	registered, err := oauth2.Get(provider)
+	if err != nil {
 		return nil, fmt.Errorf("oauth2: provider(%s) not supported", provider)
 	}
+
+	return oauth2.New(oauth2.MergeCredentials(registered, cfg))
+}
+
+// Register registers a custom provider factory.
+func Register(info *oauth2.ProviderInfo, factory oauth2.ProviderFactory) error {
+	return oauth2.RegisterProvider(info, factory)
+}
+
+// Providers returns all the available providers.
+func Providers() []*oauth2.ProviderInfo {
+	return oauth2.Providers()
 }
@@ -0,0 +1,371 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderDefinition is the declarative definition of a generic provider,
+// which can be loaded from JSON or YAML.
+type ProviderDefinition struct {
+	ID          string `json:"id" yaml:"id"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+	Icon        string `json:"icon" yaml:"icon"`
+	//
+	AuthURL     string `json:"auth_url" yaml:"auth_url"`
+	TokenURL    string `json:"token_url" yaml:"token_url"`
+	UserInfoURL string `json:"userinfo_url" yaml:"userinfo_url"`
+	LogoutURL   string `json:"logout_url" yaml:"logout_url"`
+	RegisterURL string `json:"register_url" yaml:"register_url"`
+	Issuer      string `json:"issuer" yaml:"issuer"`
+	//
+	Scopes         []string `json:"scopes" yaml:"scopes"`
+	ScopeSeparator string   `json:"scope_separator" yaml:"scope_separator"`
+	// AuthStyle is one of client_secret_post (default), client_secret_basic and none.
+	AuthStyle  string            `json:"auth_style" yaml:"auth_style"`
+	AuthParams map[string]string `json:"auth_params" yaml:"auth_params"`
+	//
+	Attributes DefinitionAttributes `json:"attributes" yaml:"attributes"`
+	// Claims are the claim mappings of the user fields, see Config.ClaimMappings.
+	Claims map[string]*ClaimMapping `json:"claims" yaml:"claims"`
+	// Roles maps the groups and claims of the user to application roles.
+	Roles *RoleMapping `json:"roles" yaml:"roles"`
+}
+
+// DefinitionAttributes are the attribute name mappings of the provider definition.
+type DefinitionAttributes struct {
+	// Request maps the authorize request parameters: client_id, client_secret, redirect_uri, response_type, scope, state.
+	Request map[string]string `json:"request" yaml:"request"`
+	// Token maps the token fields: access_token, refresh_token, expires_in, token_type, id_token, scope.
+	Token map[string]string `json:"token" yaml:"token"`
+	// User maps the user fields: id, username, email, nickname, avatar, homepage, permissions, groups
+	// and the OpenID Connect standard claims, such as given_name and email_verified.
+	User map[string]string `json:"user" yaml:"user"`
+}
+
+// definitionAttributes are the known attribute names and the Config fields they map to.
+func definitionAttributes(config *Config) map[string]map[string]*string {
+	return map[string]map[string]*string{
+		"request": {
+			"client_id":     &config.ClientIDAttributeName,
+			"client_secret": &config.ClientSecretAttributeName,
+			"redirect_uri":  &config.RedirectURIAttributeName,
+			"response_type": &config.ResponseTypeAttributeName,
+			"scope":         &config.ScopeAttributeName,
+			"state":         &config.StateAttributeName,
+		},
+		"token": {
+			"access_token":  &config.AccessTokenAttributeName,
+			"refresh_token": &config.RefreshTokenAttributeName,
+			"expires_in":    &config.ExpiresInAttributeName,
+			"token_type":    &config.TokenTypeAttributeName,
+			"id_token":      &config.IDTokenAttributeName,
+			"scope":         &config.GrantedScopeAttributeName,
+		},
+		"user": {
+			"id":             &config.IDAttributeName,
+			"username":       &config.UsernameAttributeName,
+			"email":          &config.EmailAttributeName,
+			"nickname":       &config.NicknameAttributeName,
+			"avatar":         &config.AvatarAttributeName,
+			"homepage":       &config.HomepageAttributeName,
+			"permissions":    &config.PermissionsAttributeName,
+			"groups":         &config.GroupsAttributeName,
+			"name":           &config.NameAttributeName,
+			"given_name":     &config.GivenNameAttributeName,
+			"family_name":    &config.FamilyNameAttributeName,
+			"middle_name":    &config.MiddleNameAttributeName,
+			"profile":        &config.ProfileAttributeName,
+			"email_verified": &config.EmailVerifiedAttributeName,
+			"gender":         &config.GenderAttributeName,
+			"birthdate":      &config.BirthdateAttributeName,
+			"zoneinfo":       &config.ZoneInfoAttributeName,
+			"locale":         &config.LocaleAttributeName,
+			"phone":          &config.PhoneAttributeName,
+			"phone_verified": &config.PhoneVerifiedAttributeName,
+			"address":        &config.AddressAttributeName,
+			"updated_at":     &config.UpdatedAtAttributeName,
+		},
+	}
+}
+
+func (d *ProviderDefinition) attributeGroups() map[string]map[string]string {
+	return map[string]map[string]string{
+		"request": d.Attributes.Request,
+		"token":   d.Attributes.Token,
+		"user":    d.Attributes.User,
+	}
+}
+
+// Validate validates the definition and reports all the problems together.
+func (d *ProviderDefinition) Validate() error {
+	errs := Errors{}
+	name := d.ID
+	if name == "" {
+		name = "(unnamed)"
+		errs.Add(fmt.Errorf("oauth2: provider definition id is required"))
+	}
+
+	urls := []struct {
+		field    string
+		value    string
+		required bool
+	}{
+		{"auth_url", d.AuthURL, true},
+		{"token_url", d.TokenURL, true},
+		{"userinfo_url", d.UserInfoURL, true},
+		{"logout_url", d.LogoutURL, false},
+		{"register_url", d.RegisterURL, false},
+		{"issuer", d.Issuer, false},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			if u.required {
+				errs.Add(fmt.Errorf("oauth2: provider definition(%s) %s is required", name, u.field))
+			}
+			continue
+		}
+
+		if err := validateEndpointURL(u.value); err != nil {
+			errs.Add(fmt.Errorf("oauth2: provider definition(%s) %s(%s) %s", name, u.field, u.value, err))
+		}
+	}
+
+	switch d.AuthStyle {
+	case "", AuthStyleInParams, AuthStyleInHeader, AuthStyleNone:
+	default:
+		errs.Add(fmt.Errorf("oauth2: provider definition(%s) unknown auth_style %s", name, d.AuthStyle))
+	}
+
+	known := definitionAttributes(&Config{})
+	groups := d.attributeGroups()
+	for _, group := range []string{"request", "token", "user"} {
+		attributes := groups[group]
+		keys := make([]string, 0, len(attributes))
+		for key := range attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := attributes[key]
+			if _, ok := known[group][key]; !ok {
+				errs.Add(fmt.Errorf("oauth2: provider definition(%s) unknown %s attribute %s", name, group, key))
+			} else if value == "" {
+				errs.Add(fmt.Errorf("oauth2: provider definition(%s) %s attribute %s is empty", name, group, key))
+			} else {
+				errs.Add(validateAttributeName(group+"."+key, value))
+			}
+		}
+	}
+
+	errs.Add(validateClaimMappings(fmt.Sprintf("provider definition(%s)", name), d.Claims))
+
+	if d.Roles != nil {
+		if err := d.Roles.Validate(); err != nil {
+			errs.Add(fmt.Errorf("oauth2: provider definition(%s) %s", name, err))
+		}
+	}
+
+	return errs.Err()
+}
+
+// Config creates the Config of the definition, without client credentials.
+func (d *ProviderDefinition) Config() *Config {
+	config := &Config{
+		Name:           d.DisplayName,
+		AuthURL:        d.AuthURL,
+		TokenURL:       d.TokenURL,
+		UserInfoURL:    d.UserInfoURL,
+		LogoutURL:      d.LogoutURL,
+		RegisterURL:    d.RegisterURL,
+		Issuer:         d.Issuer,
+		Scope:          strings.Join(d.Scopes, " "),
+		ScopeSeparator: d.ScopeSeparator,
+		AuthStyle:      d.AuthStyle,
+	}
+
+	if config.Name == "" {
+		config.Name = d.ID
+	}
+
+	if d.ScopeSeparator != "" {
+		config.Scope = strings.Join(d.Scopes, d.ScopeSeparator)
+	}
+
+	if len(d.AuthParams) != 0 {
+		config.AuthParams = map[string]string{}
+		for k, v := range d.AuthParams {
+			config.AuthParams[k] = v
+		}
+	}
+
+	if len(d.Claims) != 0 {
+		config.ClaimMappings = map[string]*ClaimMapping{}
+		for k, v := range d.Claims {
+			config.ClaimMappings[k] = v
+		}
+	}
+
+	config.RoleMapping = d.Roles
+
+	fields := definitionAttributes(config)
+	for group, attributes := range d.attributeGroups() {
+		for key, value := range attributes {
+			if field, ok := fields[group][key]; ok {
+				*field = value
+			}
+		}
+	}
+
+	return config
+}
+
+// ParseDefinitions parses the provider definitions in JSON or YAML (format: json, yaml),
+// both a single definition and a list of definitions are supported.
+func ParseDefinitions(data []byte, format string) ([]*ProviderDefinition, error) {
+	unmarshal := yaml.Unmarshal
+	switch strings.ToLower(format) {
+	case "json":
+		unmarshal = json.Unmarshal
+	case "yaml", "yml":
+	default:
+		return nil, fmt.Errorf("oauth2: unsupported provider definition format %s", format)
+	}
+
+	definitions := []*ProviderDefinition{}
+	if err := unmarshal(data, &definitions); err != nil {
+		definition := &ProviderDefinition{}
+		if errSingle := unmarshal(data, definition); errSingle != nil {
+			return nil, fmt.Errorf("oauth2: invalid provider definitions: %s", err)
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	errs := Errors{}
+	for _, definition := range definitions {
+		errs.Add(definition.Validate())
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+// LoadDefinitions loads the provider definitions from the JSON (.json) or YAML (.yaml, .yml) file.
+func LoadDefinitions(path string) ([]*ProviderDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read provider definitions(%s): %s", path, err)
+	}
+
+	return ParseDefinitions(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Info returns the provider metadata of the definition.
+func (d *ProviderDefinition) Info() *ProviderInfo {
+	return &ProviderInfo{
+		ID:             d.ID,
+		DisplayName:    d.DisplayName,
+		Icon:           d.Icon,
+		DefaultScopes:  d.Scopes,
+		RequiredFields: []string{"ClientID", "RedirectURI"},
+	}
+}
+
+// Factory returns the provider factory of the definition,
+// which fills the client credentials into the definition config.
+func (d *ProviderDefinition) Factory() ProviderFactory {
+	return func(cfg *Config) (Client, error) {
+		return New(MergeCredentials(d.Config(), cfg))
+	}
+}
+
+// MergeCredentials returns a copy of base with the client credentials and overrides of cfg,
+// which is used to create a client from a registered generic provider.
+func MergeCredentials(base *Config, cfg *Config) Config {
+	config := *base
+	config.ClientID = cfg.ClientID
+	config.ClientSecret = cfg.ClientSecret
+	config.RedirectURI = cfg.RedirectURI
+	if cfg.Scope != "" {
+		config.Scope = cfg.Scope
+	}
+	if cfg.PostLogoutRedirectURI != "" {
+		config.PostLogoutRedirectURI = cfg.PostLogoutRedirectURI
+	}
+	if cfg.AuthStyle != "" {
+		config.AuthStyle = cfg.AuthStyle
+	}
+	if len(cfg.ClaimMappings) != 0 {
+		config.ClaimMappings = mergeClaimMappings(base.ClaimMappings, cfg.ClaimMappings)
+	}
+	if cfg.RoleMapping != nil {
+		config.RoleMapping = cfg.RoleMapping
+	}
+	if len(cfg.ProviderOptions) != 0 {
+		config.ProviderOptions = cfg.ProviderOptions
+	}
+	if cfg.HTTPClient != nil {
+		config.HTTPClient = cfg.HTTPClient
+	}
+
+	return config
+}
+
+// RegisterDefinitions registers the provider definitions into the provider registry and the container,
+// which can be created by create.Create with the id.
+// All the definitions are validated before any is registered,
+// and the registered ones are removed again if a later one fails, so it is all or nothing.
+func RegisterDefinitions(definitions ...*ProviderDefinition) error {
+	errs := Errors{}
+	ids := map[string]bool{}
+	for _, definition := range definitions {
+		if err := definition.Validate(); err != nil {
+			errs.Add(err)
+			continue
+		}
+
+		if ids[definition.ID] {
+			errs.Add(fmt.Errorf("oauth2: provider definition(%s) is duplicated", definition.ID))
+		}
+		ids[definition.ID] = true
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	registered := []string{}
+	for _, definition := range definitions {
+		if err := RegisterProvider(definition.Info(), definition.Factory()); err != nil {
+			unregisterDefinitions(registered)
+			return err
+		}
+
+		if err := Register(definition.ID, definition.Config()); err != nil {
+			UnregisterProvider(definition.ID)
+			unregisterDefinitions(registered)
+			return err
+		}
+
+		registered = append(registered, definition.ID)
+	}
+
+	return nil
+}
+
+// unregisterDefinitions removes the registered definitions, which rolls back RegisterDefinitions.
+func unregisterDefinitions(ids []string) {
+	for _, id := range ids {
+		UnregisterProvider(id)
+		Unregister(id)
+	}
+}
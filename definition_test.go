@@ -0,0 +1,68 @@
+package oauth2
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDefinition(id string) *ProviderDefinition {
+	return &ProviderDefinition{
+		ID:          id,
+		AuthURL:     "https://idp.example.com/authorize",
+		TokenURL:    "https://idp.example.com/token",
+		UserInfoURL: "https://idp.example.com/userinfo",
+	}
+}
+
+func TestRegisterDefinitionsAllOrNothing(t *testing.T) {
+	invalid := testDefinition("definition-test-invalid")
+	invalid.TokenURL = ""
+
+	taken := testDefinition("definition-test-taken")
+	if err := RegisterDefinitions(taken); err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterDefinitions([]string{taken.ID})
+
+	cases := []struct {
+		name        string
+		definitions []*ProviderDefinition
+		want        string
+	}{
+		{
+			name:        "invalid definition",
+			definitions: []*ProviderDefinition{testDefinition("definition-test-a"), invalid},
+			want:        "token_url is required",
+		},
+		{
+			name:        "duplicated definition",
+			definitions: []*ProviderDefinition{testDefinition("definition-test-a"), testDefinition("definition-test-a")},
+			want:        "is duplicated",
+		},
+		{
+			name:        "already registered",
+			definitions: []*ProviderDefinition{testDefinition("definition-test-a"), taken},
+			want:        "already registered",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := RegisterDefinitions(c.definitions...)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("err = %v, want %s", err, c.want)
+			}
+
+			if _, _, err := LookupProvider("definition-test-a"); err == nil {
+				t.Error("definition-test-a is left in the provider registry")
+			}
+			if _, err := Get("definition-test-a"); err == nil {
+				t.Error("definition-test-a is left in the container")
+			}
+		})
+	}
+
+	if _, _, err := LookupProvider(taken.ID); err != nil {
+		t.Errorf("the earlier registration is removed: %s", err)
+	}
+}
@@ -7,10 +7,6 @@ package dingtalk
 //	https://open.dingtalk.com/document/personalapp/tutorial-on-how-to-obtain-logon-user-information-for-third-party
 
 import (
-	"fmt"
-	"net/url"
-	"strings"
-
 	"github.com/go-zoox/fetch"
 	"github.com/go-zoox/oauth2"
 )
@@ -49,18 +45,10 @@ func New(cfg *DingTalkConfig) (oauth2.Client, error) {
 		NicknameAttributeName: "nick",
 		AvatarAttributeName:   "avatarUrl",
 		HomepageAttributeName: "url",
-	}
-
-	config.GetLoginURL = func(cfg *oauth2.Config, state string) string {
-		return strings.Join([]string{
-			cfg.AuthURL,
-			fmt.Sprintf("?%s=", cfg.ClientIDAttributeName), cfg.ClientID,
-			fmt.Sprintf("&%s=", cfg.RedirectURIAttributeName), url.QueryEscape(cfg.RedirectURI),
-			fmt.Sprintf("&%s=", cfg.ResponseTypeAttributeName), "code",
-			fmt.Sprintf("&%s=", cfg.ScopeAttributeName), url.QueryEscape(scope),
-			fmt.Sprintf("&%s=", cfg.StateAttributeName), url.QueryEscape(state),
-			fmt.Sprintf("&%s=", "prompt"), "consent",
-		}, "")
+		//
+		AuthParams: map[string]string{
+			"prompt": "consent",
+		},
 	}
 
 	config.GetAccessTokenResponse = func(cfg *oauth2.Config, code, state string) (*fetch.Response, error) {
@@ -88,3 +76,20 @@ func New(cfg *DingTalkConfig) (oauth2.Client, error) {
 
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "dingtalk",
+		DisplayName:    "DingTalk",
+		Icon:           "dingtalk",
+		DefaultScopes:  []string{"snsapi_login"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		return New(&DingTalkConfig{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+		})
+	})
+}
@@ -0,0 +1,90 @@
+package oauth2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-zoox/core-utils/safe"
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// ProviderMetadata is the OpenID Connect provider metadata (discovery document).
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	RegistrationEndpoint  string `json:"registration_endpoint"`
+	RevocationEndpoint    string `json:"revocation_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+	CheckSessionIframe    string `json:"check_session_iframe"`
+	//
+	FrontChannelLogoutSupported        bool `json:"frontchannel_logout_supported"`
+	FrontChannelLogoutSessionSupported bool `json:"frontchannel_logout_session_supported"`
+	BackChannelLogoutSupported         bool `json:"backchannel_logout_supported"`
+	BackChannelLogoutSessionSupported  bool `json:"backchannel_logout_session_supported"`
+	//
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+var discoveries = safe.NewMap[string, *ProviderMetadata]()
+
+var keySets = safe.NewMap[string, *jwt.RemoteKeySet]()
+
+// Discover gets the provider metadata from {issuer}/.well-known/openid-configuration,
+// the result is cached by issuer.
+func Discover(issuer string) (*ProviderMetadata, error) {
+	if issuer == "" {
+		return nil, fmt.Errorf("oauth2: issuer is empty")
+	}
+
+	issuer = strings.TrimSuffix(issuer, "/")
+	if discoveries.Has(issuer) {
+		return discoveries.Get(issuer), nil
+	}
+
+	response, err := fetch.Get(issuer+"/.well-known/openid-configuration", &fetch.Config{
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to discover provider(%s): %s", issuer, err)
+	}
+
+	if !response.Ok() {
+		return nil, fmt.Errorf("oauth2: failed to discover provider(%s): %s", issuer, response.Error())
+	}
+
+	metadata := &ProviderMetadata{}
+	if err := response.UnmarshalJSON(metadata); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid discovery document of provider(%s): %s", issuer, err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oauth2: discovery issuer mismatch, expected %s, got %s", issuer, metadata.Issuer)
+	}
+
+	discoveries.Set(issuer, metadata)
+	return metadata, nil
+}
+
+// KeySet returns the key set of jwks_uri, which is shared by the providers with the same jwks_uri.
+func (m *ProviderMetadata) KeySet() (jwt.KeySet, error) {
+	if m.JWKSURI == "" {
+		return nil, fmt.Errorf("oauth2: provider(%s) jwks_uri is empty", m.Issuer)
+	}
+
+	if !keySets.Has(m.JWKSURI) {
+		keySets.Set(m.JWKSURI, jwt.NewRemoteKeySet(m.JWKSURI))
+	}
+
+	return keySets.Get(m.JWKSURI), nil
+}
@@ -1,6 +1,8 @@
 package doreamon
 
 import (
+	"net/url"
+
 	"github.com/go-zoox/oauth2"
 )
 
@@ -50,5 +52,39 @@ func New(cfg *DoreamonConfig) (oauth2.Client, error) {
 		GroupsAttributeName:       "groups",
 	}
 
+	// doreamon logout expects the same parameters as authorize
+	config.GetEndSessionURL = func(cfg *oauth2.Config, req *oauth2.LogoutRequest) string {
+		query := url.Values{}
+		query.Set(cfg.ClientIDAttributeName, cfg.ClientID)
+		query.Set(cfg.RedirectURIAttributeName, cfg.RedirectURI)
+		query.Set(cfg.ResponseTypeAttributeName, "code")
+		query.Set(cfg.ScopeAttributeName, cfg.Scope)
+		query.Set(cfg.StateAttributeName, req.State)
+		return cfg.LogoutURL + "?" + query.Encode()
+	}
+
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "doreamon",
+		DisplayName:    "哆啦A梦",
+		Icon:           "doreamon",
+		DefaultScopes:  []string{"openid", "email", "profile"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		version := cfg.Version
+		if version == "" {
+			version = "v2"
+		}
+
+		return New(&DoreamonConfig{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+			Version:      version,
+		})
+	})
+}
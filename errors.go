@@ -0,0 +1,69 @@
+package oauth2
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors is a list of errors, which is used to report all the problems together.
+type Errors []error
+
+// Error joins the error messages.
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors, used by errors.Is and errors.As since go 1.20.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any of the errors matches target, used by errors.Is before go 1.20.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error that matches target, used by errors.As before go 1.20.
+func (e Errors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Add adds the error if it is not nil.
+func (e *Errors) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	if errs, ok := err.(Errors); ok {
+		*e = append(*e, errs...)
+		return
+	}
+
+	*e = append(*e, err)
+}
+
+// Err returns nil if there are no errors.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -92,3 +92,20 @@ func New(cfg *FeishuConfig) (oauth2.Client, error) {
 
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "feishu",
+		DisplayName:    "飞书",
+		Icon:           "feishu",
+		DefaultScopes:  []string{"user:email"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		return New(&FeishuConfig{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"fmt"
+
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2"
+	"github.com/tidwall/gjson"
+)
+
+// perPage is the max page size of GitHub REST API.
+const perPage = 100
+
+// api is the GitHub REST API client of the user.
+type api struct {
+	BaseURL string
+}
+
+// PrimaryEmail gets the primary verified email of the user, user:email scope is required.
+func (a *api) PrimaryEmail(token *oauth2.Token) (string, error) {
+	emails, err := a.list(token, "/user/emails")
+	if err != nil {
+		return "", err
+	}
+
+	for _, email := range emails {
+		if email.Get("primary").Bool() && email.Get("verified").Bool() {
+			return email.Get("email").String(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// Organizations gets the organization logins of the user.
+func (a *api) Organizations(token *oauth2.Token) ([]string, error) {
+	orgs, err := a.list(token, "/user/orgs")
+	if err != nil {
+		return nil, err
+	}
+
+	logins := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		logins = append(logins, org.Get("login").String())
+	}
+
+	return logins, nil
+}
+
+// Teams gets the teams of the user as organization/team-slug.
+func (a *api) Teams(token *oauth2.Token) ([]string, error) {
+	teams, err := a.list(token, "/user/teams")
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(teams))
+	for _, team := range teams {
+		slugs = append(slugs, team.Get("organization.login").String()+"/"+team.Get("slug").String())
+	}
+
+	return slugs, nil
+}
+
+// list gets all the pages of the list api.
+func (a *api) list(token *oauth2.Token, path string) ([]gjson.Result, error) {
+	items := []gjson.Result{}
+	for page := 1; ; page++ {
+		response, err := fetch.Get(fmt.Sprintf("%s%s?per_page=%d&page=%d", a.BaseURL, path, perPage, page), &fetch.Config{
+			Headers: map[string]string{
+				"Authorization": "Bearer " + token.AccessToken,
+				"Accept":        "application/vnd.github+json",
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: failed to get github %s: %s", path, err)
+		}
+
+		if response.Status != 200 {
+			return nil, fmt.Errorf("oauth2: failed to get github %s: (status: %d) %s", path, response.Status, response.Get("message").String())
+		}
+
+		result := response.Get("@this").Array()
+		items = append(items, result...)
+		if len(result) < perPage {
+			return items, nil
+		}
+	}
+}
@@ -3,51 +3,168 @@ package github
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/go-zoox/oauth2"
 )
 
+// DefaultBaseURL is the url of github.com.
+const DefaultBaseURL = "https://github.com"
+
+// DefaultAPIBaseURL is the REST API url of github.com.
+const DefaultAPIBaseURL = "https://api.github.com"
+
 type GitHubConfig struct {
 	// config.Config
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
 	Scope        string `json:"scope"`
+	//
+	// BaseURL is the GitHub Enterprise Server url, such as https://github.example.com, default: https://github.com.
+	BaseURL string `json:"base_url"`
+	// APIBaseURL is the REST API url, default: https://api.github.com for github.com, or BaseURL/api/v3.
+	APIBaseURL string `json:"api_base_url"`
+	//
+	// FetchOrganizations adds the organizations of the user into User.Groups, such as go-zoox,
+	// read:org scope is required.
+	FetchOrganizations bool `json:"fetch_organizations"`
+	// FetchTeams adds the teams of the user into User.Groups, such as go-zoox/core,
+	// read:org scope is required.
+	FetchTeams bool `json:"fetch_teams"`
+	// AllowedOrganizations restricts login to the members of the organizations,
+	// read:org scope is required.
+	AllowedOrganizations []string `json:"allowed_organizations"`
 }
 
 func New(cfg *GitHubConfig) (oauth2.Client, error) {
 	scope := cfg.Scope
 	if scope == "" {
 		scope = "user:email"
+		if cfg.FetchOrganizations || cfg.FetchTeams || len(cfg.AllowedOrganizations) != 0 {
+			scope = "user:email read:org"
+		}
+	}
+
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	apiBaseURL := strings.TrimSuffix(cfg.APIBaseURL, "/")
+	if apiBaseURL == "" {
+		apiBaseURL = DefaultAPIBaseURL
+		if baseURL != DefaultBaseURL {
+			apiBaseURL = baseURL + "/api/v3"
+		}
 	}
 
 	config := oauth2.Config{
 		Name:         "GitHub",
-		AuthURL:      "https://github.com/login/oauth/authorize",
-		TokenURL:     "https://github.com/login/oauth/access_token",
-		UserInfoURL:  "https://api.github.com/user",
-		LogoutURL:    "https://github.com/logout",
+		AuthURL:      baseURL + "/login/oauth/authorize",
+		TokenURL:     baseURL + "/login/oauth/access_token",
+		UserInfoURL:  apiBaseURL + "/user",
+		LogoutURL:    baseURL + "/logout",
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
+		BaseURL:      baseURL,
+		APIBaseURL:   apiBaseURL,
 		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
 		ExpiresInAttributeName:    "expires_in",
 		TokenTypeAttributeName:    "token_type",
 		//
-		EmailAttributeName:    "email",
-		IDAttributeName:       "login",
+		EmailAttributeName: "email",
+		// id is immutable, while login changes when the user renames
+		IDAttributeName:       "id",
+		UsernameAttributeName: "login",
 		NicknameAttributeName: "name",
 		AvatarAttributeName:   "avatar_url",
 		HomepageAttributeName: "html_url",
 	}
 
 	config.GetRegisterURL = func(oac *oauth2.Config) string {
-		returnTo := fmt.Sprintf("https://github.com/login?client_id=%s", cfg.ClientID)
-		return fmt.Sprintf("https://github.com/signup?return_to=%s", url.QueryEscape(returnTo))
+		returnTo := fmt.Sprintf("%s/login?client_id=%s", baseURL, cfg.ClientID)
+		return fmt.Sprintf("%s/signup?return_to=%s", baseURL, url.QueryEscape(returnTo))
+	}
+
+	api := &api{
+		BaseURL: apiBaseURL,
+	}
+
+	config.EnrichUser = func(oac *oauth2.Config, token *oauth2.Token, user *oauth2.User) error {
+		// the email of /user is empty if the user hides it
+		if email, err := api.PrimaryEmail(token); err == nil && email != "" {
+			user.Email = email
+			user.EmailVerified = true
+		}
+
+		if cfg.FetchOrganizations || len(cfg.AllowedOrganizations) != 0 {
+			orgs, err := api.Organizations(token)
+			if err != nil {
+				return err
+			}
+
+			if len(cfg.AllowedOrganizations) != 0 && !containsAny(orgs, cfg.AllowedOrganizations) {
+				return fmt.Errorf("%w, user(%s) is not a member of the organizations: %s", oauth2.ErrUserAccessDenied, user.Username, strings.Join(cfg.AllowedOrganizations, ", "))
+			}
+
+			if cfg.FetchOrganizations {
+				user.Groups = append(user.Groups, orgs...)
+			}
+		}
+
+		if cfg.FetchTeams {
+			teams, err := api.Teams(token)
+			if err != nil {
+				return err
+			}
+
+			user.Groups = append(user.Groups, teams...)
+		}
+
+		return nil
 	}
 
 	return oauth2.New(config)
 }
+
+// containsAny reports whether any of the values is in the list, case insensitive as GitHub logins.
+func containsAny(list []string, values []string) bool {
+	for _, item := range list {
+		for _, value := range values {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "github",
+		DisplayName:    "GitHub",
+		Icon:           "github",
+		DefaultScopes:  []string{"user:email"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		// fetch_organizations, fetch_teams and allowed_organizations
+		config := &GitHubConfig{}
+		if err := oauth2.DecodeProviderOptions(cfg, config); err != nil {
+			return nil, err
+		}
+
+		config.ClientID = cfg.ClientID
+		config.ClientSecret = cfg.ClientSecret
+		config.RedirectURI = cfg.RedirectURI
+		config.Scope = cfg.Scope
+		config.BaseURL = cfg.BaseURL
+		config.APIBaseURL = cfg.APIBaseURL
+		return New(config)
+	})
+}
@@ -0,0 +1,44 @@
+package gitlab
+
+import (
+	"fmt"
+
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2"
+)
+
+// perPage is the max page size of GitLab REST API.
+const perPage = 100
+
+// api is the GitLab REST API client of the user.
+type api struct {
+	BaseURL string
+}
+
+// Groups gets the full path of the groups, which the user is a member of with at least the access level.
+func (a *api) Groups(token *oauth2.Token, minAccessLevel int) ([]string, error) {
+	groups := []string{}
+	for page := 1; ; page++ {
+		response, err := fetch.Get(fmt.Sprintf("%s/groups?min_access_level=%d&per_page=%d&page=%d", a.BaseURL, minAccessLevel, perPage, page), &fetch.Config{
+			Headers: map[string]string{
+				"Authorization": "Bearer " + token.AccessToken,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: failed to get gitlab groups: %s", err)
+		}
+
+		if response.Status != 200 {
+			return nil, fmt.Errorf("oauth2: failed to get gitlab groups: (status: %d) %s", response.Status, response.Get("message").String())
+		}
+
+		result := response.Get("@this").Array()
+		for _, group := range result {
+			groups = append(groups, group.Get("full_path").String())
+		}
+
+		if len(result) < perPage {
+			return groups, nil
+		}
+	}
+}
@@ -1,33 +1,73 @@
 package gitlab
 
 import (
+	"strings"
+
 	"github.com/go-zoox/oauth2"
 )
 
+// DefaultBaseURL is the url of gitlab.com.
+const DefaultBaseURL = "https://gitlab.com"
+
+// Group access levels of GitLab.
+const (
+	AccessLevelGuest      = 10
+	AccessLevelReporter   = 20
+	AccessLevelDeveloper  = 30
+	AccessLevelMaintainer = 40
+	AccessLevelOwner      = 50
+)
+
 type GitLabConfig struct {
 	// config.Config
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
 	Scope        string `json:"scope"`
+	//
+	// BaseURL is the self-managed GitLab url, such as https://gitlab.example.com, default: https://gitlab.com.
+	BaseURL string `json:"base_url"`
+	// APIBaseURL is the REST API url, default: BaseURL/api/v4.
+	APIBaseURL string `json:"api_base_url"`
+	//
+	// FetchGroups adds the full path of the groups of the user into User.Groups, such as go-zoox/core,
+	// read_api scope is required.
+	FetchGroups bool `json:"fetch_groups"`
+	// GroupsMinAccessLevel is the min access level of the fetched groups, default: AccessLevelGuest.
+	GroupsMinAccessLevel int `json:"groups_min_access_level"`
 }
 
 func New(cfg *GitLabConfig) (oauth2.Client, error) {
 	scope := cfg.Scope
 	if scope == "" {
 		scope = "read_user profile"
+		if cfg.FetchGroups {
+			scope = "read_user profile read_api"
+		}
+	}
+
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	apiBaseURL := strings.TrimSuffix(cfg.APIBaseURL, "/")
+	if apiBaseURL == "" {
+		apiBaseURL = baseURL + "/api/v4"
 	}
 
 	config := oauth2.Config{
 		Name:         "GitLab",
-		AuthURL:      "https://gitlab.com/oauth/authorize",
-		TokenURL:     "https://gitlab.com/oauth/token",
-		UserInfoURL:  "https://gitlab.com/api/v4/user",
-		LogoutURL:    "https://gitlab.com/logout",
+		AuthURL:      baseURL + "/oauth/authorize",
+		TokenURL:     baseURL + "/oauth/token",
+		UserInfoURL:  apiBaseURL + "/user",
+		LogoutURL:    baseURL + "/logout",
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
+		BaseURL:      baseURL,
+		APIBaseURL:   apiBaseURL,
 		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
@@ -41,5 +81,50 @@ func New(cfg *GitLabConfig) (oauth2.Client, error) {
 		HomepageAttributeName: "web_url",
 	}
 
+	if cfg.FetchGroups {
+		minAccessLevel := cfg.GroupsMinAccessLevel
+		if minAccessLevel == 0 {
+			minAccessLevel = AccessLevelGuest
+		}
+
+		api := &api{
+			BaseURL: apiBaseURL,
+		}
+
+		config.EnrichUser = func(oac *oauth2.Config, token *oauth2.Token, user *oauth2.User) error {
+			groups, err := api.Groups(token, minAccessLevel)
+			if err != nil {
+				return err
+			}
+
+			user.Groups = append(user.Groups, groups...)
+			return nil
+		}
+	}
+
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "gitlab",
+		DisplayName:    "GitLab",
+		Icon:           "gitlab",
+		DefaultScopes:  []string{"read_user", "profile"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		// fetch_groups and groups_min_access_level
+		config := &GitLabConfig{}
+		if err := oauth2.DecodeProviderOptions(cfg, config); err != nil {
+			return nil, err
+		}
+
+		config.ClientID = cfg.ClientID
+		config.ClientSecret = cfg.ClientSecret
+		config.RedirectURI = cfg.RedirectURI
+		config.Scope = cfg.Scope
+		config.BaseURL = cfg.BaseURL
+		config.APIBaseURL = cfg.APIBaseURL
+		return New(config)
+	})
+}
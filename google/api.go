@@ -0,0 +1,63 @@
+package google
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// directoryGroupsURL is the groups api of Google Workspace Directory API.
+var directoryGroupsURL = "https://admin.googleapis.com/admin/directory/v1/groups"
+
+// verifyIDToken verifies the id_token, both iss of Google are allowed.
+func verifyIDToken(config *oauth2.Config, token *oauth2.Token) (jwt.Claims, error) {
+	if token.IDToken == "" {
+		return nil, oauth2.ErrIDTokenEmpty
+	}
+
+	verifier, err := config.IDTokenVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier.Issuers = append(verifier.Issuers, "accounts.google.com")
+	verifier.Nonce = token.Nonce()
+	return verifier.Verify(token.IDToken)
+}
+
+// directoryGroups gets the group emails of the user from Directory API.
+func directoryGroups(token *oauth2.Token, email string) ([]string, error) {
+	groups := []string{}
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("userKey", email)
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		response, err := fetch.Get(directoryGroupsURL+"?"+query.Encode(), &fetch.Config{
+			Headers: map[string]string{
+				"Authorization": "Bearer " + token.AccessToken,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: failed to get google groups: %s", err)
+		}
+
+		if response.Status != 200 {
+			return nil, fmt.Errorf("oauth2: failed to get google groups: (status: %d) %s", response.Status, response.Get("error.message").String())
+		}
+
+		for _, group := range response.Get("groups").Array() {
+			groups = append(groups, group.Get("email").String())
+		}
+
+		if pageToken = response.Get("nextPageToken").String(); pageToken == "" {
+			return groups, nil
+		}
+	}
+}
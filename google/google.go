@@ -1,45 +1,186 @@
 package google
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/go-zoox/logger"
 	"github.com/go-zoox/oauth2"
 )
 
+// Issuer is the OpenID Connect issuer of Google.
+const Issuer = "https://accounts.google.com"
+
+// The endpoints of the discovery document of Issuer, used when the discovery fails.
+const (
+	AuthURL     = "https://accounts.google.com/o/oauth2/v2/auth"
+	TokenURL    = "https://oauth2.googleapis.com/token"
+	UserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+	LogoutURL   = "https://accounts.google.com/logout"
+)
+
+// DirectoryGroupScope is the scope required by FetchGroups.
+const DirectoryGroupScope = "https://www.googleapis.com/auth/admin.directory.group.readonly"
+
 type GoogleConfig struct {
 	// config.Config
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
 	Scope        string `json:"scope"`
+	//
+	// HostedDomains restricts login to the Google Workspace domains, such as example.com,
+	// which is enforced by the hd claim of the verified id_token.
+	HostedDomains []string `json:"hosted_domains"`
+	// Offline requests a refresh token by access_type=offline and prompt=consent.
+	Offline bool `json:"offline"`
+	// FetchGroups adds the group emails of the user from Directory API into User.Groups,
+	// DirectoryGroupScope is required.
+	FetchGroups bool `json:"fetch_groups"`
+}
+
+// WithOffline requests a refresh token for the authorize request, see GoogleConfig.Offline.
+func WithOffline() oauth2.Option {
+	return func(opts *oauth2.Options) {
+		oauth2.WithParam("access_type", "offline")(opts)
+		oauth2.WithPrompt(oauth2.PromptConsent)(opts)
+	}
 }
 
 func New(cfg *GoogleConfig) (oauth2.Client, error) {
 	scope := cfg.Scope
 	if scope == "" {
-		scope = "https://www.googleapis.com/auth/userinfo.email https://www.googleapis.com/auth/userinfo.profile"
+		scope = "openid email profile"
+		if cfg.FetchGroups {
+			scope += " " + DirectoryGroupScope
+		}
 	}
 
 	config := oauth2.Config{
 		Name:         "Google",
-		AuthURL:      "https://accounts.google.com/o/oauth2/auth",
-		TokenURL:     "https://accounts.google.com/o/oauth2/token",
-		UserInfoURL:  "https://www.googleapis.com/oauth2/v1/userinfo",
-		LogoutURL:    "https://accounts.google.com/logout",
+		AuthURL:      AuthURL,
+		TokenURL:     TokenURL,
+		UserInfoURL:  UserInfoURL,
+		LogoutURL:    LogoutURL,
+		Issuer:       Issuer,
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
 		//
+		IncludeGrantedScopes: true,
+		AuthParams:           map[string]string{},
+		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
 		ExpiresInAttributeName:    "expires_in",
 		TokenTypeAttributeName:    "token_type",
 		//
 		EmailAttributeName:    "email",
-		IDAttributeName:       "id",
+		IDAttributeName:       "sub",
 		NicknameAttributeName: "name",
 		AvatarAttributeName:   "picture",
 		// HomepageAttributeName: "web_url",
 	}
 
+	discoverEndpoints(&config)
+
+	// hd is only a hint of the account chooser, * for multiple domains
+	switch len(cfg.HostedDomains) {
+	case 0:
+	case 1:
+		config.AuthParams["hd"] = cfg.HostedDomains[0]
+	default:
+		config.AuthParams["hd"] = "*"
+	}
+
+	if cfg.Offline {
+		config.AuthParams["access_type"] = "offline"
+		config.AuthParams["prompt"] = oauth2.PromptConsent
+	}
+
+	config.EnrichUser = func(oac *oauth2.Config, token *oauth2.Token, user *oauth2.User) error {
+		if token.IDToken != "" || len(cfg.HostedDomains) != 0 {
+			claims, err := verifyIDToken(oac, token)
+			if err != nil {
+				return err
+			}
+
+			if claims.String("sub") != user.ID {
+				return fmt.Errorf("oauth2: google id_token sub(%s) does not match the user(%s)", claims.String("sub"), user.ID)
+			}
+
+			if len(cfg.HostedDomains) != 0 && !containsFold(cfg.HostedDomains, claims.String("hd")) {
+				return fmt.Errorf("%w, google hosted domain(%s) is not allowed", oauth2.ErrUserAccessDenied, claims.String("hd"))
+			}
+		}
+
+		if cfg.FetchGroups {
+			groups, err := directoryGroups(token, user.Email)
+			if err != nil {
+				return err
+			}
+
+			user.Groups = append(user.Groups, groups...)
+		}
+
+		return nil
+	}
+
 	return oauth2.New(config)
 }
+
+// discoverEndpoints uses the endpoints of the discovery document of Issuer, which is cached,
+// and keeps the constants if the discovery fails.
+func discoverEndpoints(config *oauth2.Config) {
+	metadata, err := oauth2.Discover(Issuer)
+	if err != nil {
+		logger.Warnf("[oauth2][google] use the default endpoints, failed to discover: %s", err)
+		return
+	}
+
+	if metadata.AuthorizationEndpoint != "" {
+		config.AuthURL = metadata.AuthorizationEndpoint
+	}
+	if metadata.TokenEndpoint != "" {
+		config.TokenURL = metadata.TokenEndpoint
+	}
+	if metadata.UserInfoEndpoint != "" {
+		config.UserInfoURL = metadata.UserInfoEndpoint
+	}
+	if metadata.EndSessionEndpoint != "" {
+		config.LogoutURL = metadata.EndSessionEndpoint
+	}
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if value != "" && strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "google",
+		DisplayName:    "Google",
+		Icon:           "google",
+		DefaultScopes:  []string{"openid", "email", "profile"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		// hosted_domains, offline and fetch_groups
+		config := &GoogleConfig{}
+		if err := oauth2.DecodeProviderOptions(cfg, config); err != nil {
+			return nil, err
+		}
+
+		config.ClientID = cfg.ClientID
+		config.ClientSecret = cfg.ClientSecret
+		config.RedirectURI = cfg.RedirectURI
+		config.Scope = cfg.Scope
+		return New(config)
+	})
+}
@@ -2,10 +2,10 @@ package doreamon
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -13,14 +13,25 @@ import (
 	"github.com/go-zoox/cookie"
 	"github.com/go-zoox/logger"
 	"github.com/go-zoox/oauth2"
+	"github.com/go-zoox/oauth2/config"
 	"github.com/go-zoox/oauth2/doreamon"
 )
 
+// DefaultCookieKey is the cookie key of the token saved by the handler.
+const DefaultCookieKey = "go-zoox_oauth2_token"
+
 type CreateOAuth2DoreamonHandlerConfig struct {
 	ApplicationName string
 	ClientID        string
 	ClientSecret    string
 	RedirectURI     string
+	// RoleMapping maps the doreamon groups and permissions to application roles.
+	RoleMapping *oauth2.RoleMapping
+	// RequiredRoles are the roles, any of which is required to login.
+	RequiredRoles []string
+	// TokenStore saves the token of the login user by user id, optional,
+	// use oauth2.StoredTokenSource to get the refreshed token.
+	TokenStore oauth2.TokenStore
 }
 
 type VerifyUserConfig struct {
@@ -69,7 +80,15 @@ func CreateOAuth2DoreamonHandler(cfg *CreateOAuth2DoreamonHandlerConfig) func(
 		panic(err)
 	}
 
-	CookieKey := "go-zoox_oauth2_token"
+	if cfg.RoleMapping != nil {
+		clientCfg := client.Config()
+		clientCfg.RoleMapping = cfg.RoleMapping
+		if err := client.Update(*clientCfg); err != nil {
+			panic(err)
+		}
+	}
+
+	CookieKey := DefaultCookieKey
 	VerifyUserCfg := &VerifyUserConfig{
 		CookieKey: CookieKey,
 	}
@@ -143,6 +162,13 @@ func CreateOAuth2DoreamonHandler(cfg *CreateOAuth2DoreamonHandlerConfig) func(
 
 			logger.Infof("[oauth2] login callback ...")
 			client.Callback(code, state, func(user *oauth2.User, token *oauth2.Token, err error) {
+				if errors.Is(err, oauth2.ErrUserAccessDenied) {
+					logger.Infof("[oauth2] user access denied: %s", err)
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte("Forbidden"))
+					return
+				}
+
 				if err != nil {
 					log.Println("[OAUTH2] Login Callback Error", err)
 					time.Sleep(3 * time.Second)
@@ -150,6 +176,22 @@ func CreateOAuth2DoreamonHandler(cfg *CreateOAuth2DoreamonHandlerConfig) func(
 					return
 				}
 
+				if len(cfg.RequiredRoles) != 0 && !user.HasAnyRole(cfg.RequiredRoles...) {
+					logger.Infof("[oauth2] user(%s) has none of the required roles: %v", user.Username, cfg.RequiredRoles)
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte("Forbidden"))
+					return
+				}
+
+				if cfg.TokenStore != nil {
+					if err := cfg.TokenStore.Save(oauth2.TokenKey{User: user.ID, Provider: "doreamon"}, token); err != nil {
+						logger.Errorf("[oauth2] failed to store token: %s", err)
+						w.WriteHeader(500)
+						w.Write([]byte("Failed to store token: " + user.Email))
+						return
+					}
+				}
+
 				SaveUserCfg.Token = &Token{
 					CookieKey: CookieKey,
 					Cookie: func() cookie.Cookie {
@@ -233,21 +275,17 @@ func CreateHTTPHandler(
 	SaveUser func(cfg *SaveUserConfig, user *oauth2.User, token *oauth2.Token, r *http.Request, w http.ResponseWriter) (tokenString string, err error),
 	Next func(w http.ResponseWriter, r *http.Request) error,
 ) http.Handler {
-	if os.Getenv("DOREAMON_CLIENT_ID") == "" {
-		panic("env DOREAMON_CLIENT_ID is required")
-	}
-	if os.Getenv("DOREAMON_CLIENT_SECRET") == "" {
-		panic("env DOREAMON_CLIENT_SECRET is required")
-	}
-	if os.Getenv("DOREAMON_REDIRECT_URI") == "" {
-		panic("env DOREAMON_REDIRECT_URI is required")
+	// env: DOREAMON_CLIENT_ID, DOREAMON_CLIENT_SECRET, DOREAMON_REDIRECT_URI
+	cfg, err := config.FromEnv("", "doreamon")
+	if err != nil {
+		panic(err)
 	}
 
 	handler := CreateOAuth2DoreamonHandler(&CreateOAuth2DoreamonHandlerConfig{
 		ApplicationName: ApplicationName,
-		ClientID:        os.Getenv("DOREAMON_CLIENT_ID"),
-		ClientSecret:    os.Getenv("DOREAMON_CLIENT_SECRET"),
-		RedirectURI:     os.Getenv("DOREAMON_REDIRECT_URI"),
+		ClientID:        cfg.ClientID,
+		ClientSecret:    cfg.ClientSecret,
+		RedirectURI:     cfg.RedirectURI,
 	})
 
 	hfn := func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,283 @@
+package logout
+
+// reference: https://openid.net/specs/openid-connect-backchannel-1_0.html
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-zoox/logger"
+	"github.com/go-zoox/oauth2"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// BackChannelLogoutEvent is the member of events claim in logout token.
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// BackChannelConfig is the config of back-channel logout handler.
+type BackChannelConfig struct {
+	// Issuer is the expected iss of logout token.
+	Issuer string
+	// ClientID is the expected aud of logout token.
+	ClientID string
+	// KeySet verifies the signature of logout token, default: jwks_uri from the discovery of Issuer.
+	KeySet jwt.KeySet
+	// MaxAge is the max age of the iat claim, default: 5 minutes.
+	MaxAge time.Duration
+	// Leeway is the allowed clock skew, default: 1 minute.
+	Leeway time.Duration
+	// ReplayCache detects replayed logout tokens by jti, default: in-memory.
+	ReplayCache ReplayCache
+	// TerminateSessions destroys all the sessions of the sid (when present) or sub.
+	TerminateSessions func(ctx context.Context, token *LogoutToken) error
+}
+
+// LogoutToken is the verified logout token.
+type LogoutToken struct {
+	Issuer    string
+	Audience  []string
+	Subject   string
+	SessionID string
+	JWTID     string
+	IssuedAt  time.Time
+	Claims    jwt.Claims
+}
+
+// ReplayCache records the seen jti of logout tokens.
+type ReplayCache interface {
+	// Seen reports whether jti has been seen, and records it until expiresAt otherwise.
+	Seen(jti string, expiresAt time.Time) bool
+	// Forget removes the recorded jti, so the retry of a failed logout is accepted.
+	Forget(jti string)
+}
+
+// NewMemoryReplayCache creates an in-memory ReplayCache.
+func NewMemoryReplayCache() ReplayCache {
+	return &memoryReplayCache{
+		entries: map[string]time.Time{},
+	}
+}
+
+type memoryReplayCache struct {
+	sync.Mutex
+	entries map[string]time.Time
+}
+
+func (c *memoryReplayCache) Seen(jti string, expiresAt time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	for key, expires := range c.entries {
+		if now.After(expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	if _, ok := c.entries[jti]; ok {
+		return true
+	}
+
+	c.entries[jti] = expiresAt
+	return false
+}
+
+func (c *memoryReplayCache) Forget(jti string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.entries, jti)
+}
+
+// BackChannelHandler receives the back-channel logout requests.
+type BackChannelHandler struct {
+	cfg *BackChannelConfig
+	//
+	sync.Mutex
+	keySet jwt.KeySet
+}
+
+// NewBackChannelHandler creates the back-channel logout handler.
+func NewBackChannelHandler(cfg *BackChannelConfig) (*BackChannelHandler, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oauth2: back-channel logout issuer is required")
+	}
+
+	if cfg.ClientID == "" {
+		return nil, errors.New("oauth2: back-channel logout client id is required")
+	}
+
+	if cfg.TerminateSessions == nil {
+		return nil, errors.New("oauth2: back-channel logout TerminateSessions is required")
+	}
+
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = 5 * time.Minute
+	}
+
+	if cfg.Leeway == 0 {
+		cfg.Leeway = time.Minute
+	}
+
+	if cfg.ReplayCache == nil {
+		cfg.ReplayCache = NewMemoryReplayCache()
+	}
+
+	return &BackChannelHandler{
+		cfg:    cfg,
+		keySet: cfg.KeySet,
+	}, nil
+}
+
+// ServeHTTP handles the logout_token POST from the provider.
+func (h *BackChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+		return
+	}
+
+	token, err := h.VerifyLogoutToken(r.PostFormValue("logout_token"))
+	if err != nil {
+		logger.Infof("[oauth2][back-channel logout] invalid logout token: %s", err)
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := h.cfg.TerminateSessions(r.Context(), token); err != nil {
+		// the provider retries the same logout token
+		h.cfg.ReplayCache.Forget(token.JWTID)
+
+		logger.Errorf("[oauth2][back-channel logout] failed to terminate sessions(sid: %s, sub: %s): %s", token.SessionID, token.Subject, err)
+		writeError(w, http.StatusBadRequest, "logout_failed", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// VerifyLogoutToken verifies the logout token and records its jti,
+// call ReplayCache.Forget with the jti if the sessions are not terminated.
+func (h *BackChannelHandler) VerifyLogoutToken(raw string) (*LogoutToken, error) {
+	if raw == "" {
+		return nil, errors.New("logout_token is required")
+	}
+
+	keySet, err := h.getKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Verify(raw, keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ := token.Header.Type; typ != "" && typ != "JWT" && typ != "logout+jwt" {
+		return nil, fmt.Errorf("unexpected typ %s", typ)
+	}
+
+	claims := token.Claims
+	if iss := claims.String("iss"); iss != h.cfg.Issuer {
+		return nil, fmt.Errorf("unexpected iss %s", iss)
+	}
+
+	if !claims.HasAudience(h.cfg.ClientID) {
+		return nil, fmt.Errorf("unexpected aud %v", claims.Audience())
+	}
+
+	now := time.Now()
+	if err := claims.ValidateTime(now, h.cfg.Leeway); err != nil {
+		return nil, err
+	}
+
+	iat := claims.Time("iat")
+	if iat.IsZero() {
+		return nil, errors.New("iat is required")
+	}
+	if now.Sub(iat) > h.cfg.MaxAge+h.cfg.Leeway || iat.Sub(now) > h.cfg.Leeway {
+		return nil, fmt.Errorf("iat %s is out of range", iat)
+	}
+
+	events, ok := claims["events"].(map[string]any)
+	if !ok {
+		return nil, errors.New("events is required")
+	}
+	if _, ok := events[BackChannelLogoutEvent].(map[string]any); !ok {
+		return nil, fmt.Errorf("events must contain %s", BackChannelLogoutEvent)
+	}
+
+	sid := claims.String("sid")
+	sub := claims.String("sub")
+	if sid == "" && sub == "" {
+		return nil, errors.New("sid or sub is required")
+	}
+
+	if claims.Has("nonce") {
+		return nil, errors.New("nonce is prohibited")
+	}
+
+	jti := claims.String("jti")
+	if jti == "" {
+		return nil, errors.New("jti is required")
+	}
+
+	expiresAt := claims.Time("exp")
+	if expiresAt.IsZero() {
+		expiresAt = iat.Add(h.cfg.MaxAge)
+	}
+	if h.cfg.ReplayCache.Seen(jti, expiresAt.Add(h.cfg.Leeway)) {
+		return nil, fmt.Errorf("logout token(jti: %s) is replayed", jti)
+	}
+
+	return &LogoutToken{
+		Issuer:    claims.String("iss"),
+		Audience:  claims.Audience(),
+		Subject:   sub,
+		SessionID: sid,
+		JWTID:     jti,
+		IssuedAt:  iat,
+		Claims:    claims,
+	}, nil
+}
+
+// getKeySet returns the configured key set or the one from discovery.
+func (h *BackChannelHandler) getKeySet() (jwt.KeySet, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.keySet != nil {
+		return h.keySet, nil
+	}
+
+	metadata, err := oauth2.Discover(h.cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet, err := metadata.KeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	h.keySet = keySet
+	return keySet, nil
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	data, _ := json.Marshal(map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
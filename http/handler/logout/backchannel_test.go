@@ -0,0 +1,282 @@
+package logout
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testClientID = "client"
+)
+
+type backChannelTest struct {
+	t          *testing.T
+	key        *ecdsa.PrivateKey
+	handler    *BackChannelHandler
+	terminated []*LogoutToken
+	terminate  error
+}
+
+func newBackChannelTest(t *testing.T) *backChannelTest {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt := &backChannelTest{
+		t:   t,
+		key: key,
+	}
+
+	bt.handler, err = NewBackChannelHandler(&BackChannelConfig{
+		Issuer:   testIssuer,
+		ClientID: testClientID,
+		KeySet:   &jwt.StaticKeySet{PublicKey: &key.PublicKey},
+		TerminateSessions: func(ctx context.Context, token *LogoutToken) error {
+			if bt.terminate != nil {
+				return bt.terminate
+			}
+
+			bt.terminated = append(bt.terminated, token)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bt
+}
+
+// claims returns the valid claims of a logout token.
+func (bt *backChannelTest) claims() jwt.Claims {
+	return jwt.Claims{
+		"iss": testIssuer,
+		"aud": testClientID,
+		"sub": "user",
+		"sid": "session",
+		"jti": "jti-1",
+		"iat": float64(time.Now().Unix()),
+		"exp": float64(time.Now().Add(2 * time.Minute).Unix()),
+		"events": map[string]any{
+			BackChannelLogoutEvent: map[string]any{},
+		},
+	}
+}
+
+func (bt *backChannelTest) sign(claims jwt.Claims) string {
+	bt.t.Helper()
+
+	raw, err := jwt.Sign(&jwt.Header{Algorithm: jwt.ES256, Type: "logout+jwt"}, claims, bt.key)
+	if err != nil {
+		bt.t.Fatal(err)
+	}
+
+	return raw
+}
+
+func (bt *backChannelTest) post(logoutToken string) *httptest.ResponseRecorder {
+	form := url.Values{}
+	form.Set("logout_token", logoutToken)
+
+	request := httptest.NewRequest(http.MethodPost, "/logout/backchannel", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	recorder := httptest.NewRecorder()
+	bt.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestBackChannelLogoutTokenValidation(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(claims jwt.Claims)
+		want   string
+	}{
+		{
+			name:   "valid",
+			modify: func(claims jwt.Claims) {},
+		},
+		{
+			name:   "sid only",
+			modify: func(claims jwt.Claims) { delete(claims, "sub") },
+		},
+		{
+			name:   "missing events",
+			modify: func(claims jwt.Claims) { delete(claims, "events") },
+			want:   "events is required",
+		},
+		{
+			name: "wrong event",
+			modify: func(claims jwt.Claims) {
+				claims["events"] = map[string]any{"http://schemas.openid.net/event/other": map[string]any{}}
+			},
+			want: "events must contain",
+		},
+		{
+			name:   "events is not an object",
+			modify: func(claims jwt.Claims) { claims["events"] = BackChannelLogoutEvent },
+			want:   "events is required",
+		},
+		{
+			name:   "nonce",
+			modify: func(claims jwt.Claims) { claims["nonce"] = "n" },
+			want:   "nonce is prohibited",
+		},
+		{
+			name: "missing sid and sub",
+			modify: func(claims jwt.Claims) {
+				delete(claims, "sid")
+				delete(claims, "sub")
+			},
+			want: "sid or sub is required",
+		},
+		{
+			name:   "missing jti",
+			modify: func(claims jwt.Claims) { delete(claims, "jti") },
+			want:   "jti is required",
+		},
+		{
+			name:   "wrong issuer",
+			modify: func(claims jwt.Claims) { claims["iss"] = "https://evil.example.com" },
+			want:   "unexpected iss",
+		},
+		{
+			name:   "wrong audience",
+			modify: func(claims jwt.Claims) { claims["aud"] = "other" },
+			want:   "unexpected aud",
+		},
+		{
+			name:   "missing iat",
+			modify: func(claims jwt.Claims) { delete(claims, "iat") },
+			want:   "iat is required",
+		},
+		{
+			name:   "too old",
+			modify: func(claims jwt.Claims) { claims["iat"] = float64(time.Now().Add(-time.Hour).Unix()) },
+			want:   "out of range",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bt := newBackChannelTest(t)
+			claims := bt.claims()
+			c.modify(claims)
+
+			recorder := bt.post(bt.sign(claims))
+			if c.want == "" {
+				if recorder.Code != http.StatusOK {
+					t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+				}
+				if len(bt.terminated) != 1 {
+					t.Fatalf("terminated %d times, want 1", len(bt.terminated))
+				}
+				return
+			}
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", recorder.Code)
+			}
+
+			body := map[string]string{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body["error"] != "invalid_request" || !strings.Contains(body["error_description"], c.want) {
+				t.Errorf("unexpected error %v, want %s", body, c.want)
+			}
+			if len(bt.terminated) != 0 {
+				t.Error("sessions are terminated by an invalid logout token")
+			}
+		})
+	}
+}
+
+func TestBackChannelLogoutReplay(t *testing.T) {
+	bt := newBackChannelTest(t)
+	token := bt.sign(bt.claims())
+
+	if recorder := bt.post(token); recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder := bt.post(token)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "replayed") {
+		t.Fatalf("replay is accepted: %d %s", recorder.Code, recorder.Body)
+	}
+
+	if len(bt.terminated) != 1 {
+		t.Errorf("terminated %d times, want 1", len(bt.terminated))
+	}
+}
+
+func TestBackChannelLogoutRetryAfterFailure(t *testing.T) {
+	bt := newBackChannelTest(t)
+	token := bt.sign(bt.claims())
+
+	bt.terminate = errors.New("session store is down")
+	recorder := bt.post(token)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "logout_failed") {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	// the jti is forgotten, so the retry of the provider is accepted
+	bt.terminate = nil
+	if recorder := bt.post(token); recorder.Code != http.StatusOK {
+		t.Fatalf("retry status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	if len(bt.terminated) != 1 || bt.terminated[0].SessionID != "session" {
+		t.Errorf("unexpected terminated sessions %v", bt.terminated)
+	}
+}
+
+func TestBackChannelLogoutMethod(t *testing.T) {
+	bt := newBackChannelTest(t)
+
+	recorder := httptest.NewRecorder()
+	bt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/logout/backchannel", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", recorder.Code)
+	}
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	cache := NewMemoryReplayCache()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if cache.Seen("a", expiresAt) {
+		t.Fatal("a is seen before recorded")
+	}
+	if !cache.Seen("a", expiresAt) {
+		t.Fatal("a is not seen after recorded")
+	}
+
+	cache.Forget("a")
+	if cache.Seen("a", expiresAt) {
+		t.Fatal("a is seen after forgotten")
+	}
+
+	if cache.Seen("expired", time.Now().Add(-time.Second)) {
+		t.Fatal("expired is seen before recorded")
+	}
+	if cache.Seen("expired", expiresAt) {
+		t.Fatal("expired entry is not pruned")
+	}
+}
@@ -0,0 +1,99 @@
+package logout
+
+// reference: https://openid.net/specs/openid-connect-frontchannel-1_0.html
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-zoox/cookie"
+	"github.com/go-zoox/logger"
+	handler "github.com/go-zoox/oauth2/http/handler/doreamon"
+)
+
+// FrontChannelConfig is the config of front-channel logout handler.
+type FrontChannelConfig struct {
+	// Issuer is the expected iss parameter.
+	Issuer string
+	// RequireSession means iss and sid parameters are required (frontchannel_logout_session_required).
+	RequireSession bool
+	// CookieKeys are the local session cookies to clear, default: the token cookie of doreamon handler.
+	CookieKeys []string
+	// SessionID returns the sid of the local session, which must match the sid parameter.
+	SessionID func(r *http.Request) string
+	// OnLogout is called after the local session is cleared, optional.
+	OnLogout func(w http.ResponseWriter, r *http.Request, sid string) error
+}
+
+// FrontChannelHandler serves the front-channel logout uri,
+// which is rendered by the provider in an iframe.
+type FrontChannelHandler struct {
+	cfg *FrontChannelConfig
+}
+
+// NewFrontChannelHandler creates the front-channel logout handler.
+func NewFrontChannelHandler(cfg *FrontChannelConfig) (*FrontChannelHandler, error) {
+	if cfg.RequireSession && cfg.Issuer == "" {
+		return nil, errors.New("oauth2: front-channel logout issuer is required when session is required")
+	}
+
+	if len(cfg.CookieKeys) == 0 {
+		cfg.CookieKeys = []string{handler.DefaultCookieKey, SessionStateCookieKey}
+	}
+
+	return &FrontChannelHandler{
+		cfg: cfg,
+	}, nil
+}
+
+// ServeHTTP validates the iss and sid parameters and clears the local session.
+func (h *FrontChannelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	iss := r.URL.Query().Get("iss")
+	sid := r.URL.Query().Get("sid")
+	if h.cfg.RequireSession && (iss == "" || sid == "") {
+		logger.Infof("[oauth2][front-channel logout] iss and sid are required")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if iss != "" && h.cfg.Issuer != "" && iss != h.cfg.Issuer {
+		logger.Infof("[oauth2][front-channel logout] unexpected iss %s", iss)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if sid != "" && h.cfg.SessionID != nil {
+		if current := h.cfg.SessionID(r); current != sid {
+			// the session has already been changed, nothing to logout
+			logger.Infof("[oauth2][front-channel logout] sid %s does not match the local session", sid)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	c := cookie.New(w, r)
+	for _, key := range h.cfg.CookieKeys {
+		c.Del(key)
+	}
+
+	if h.cfg.OnLogout != nil {
+		if err := h.cfg.OnLogout(w, r, sid); err != nil {
+			logger.Errorf("[oauth2][front-channel logout] failed to logout(sid: %s): %s", sid, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("<!DOCTYPE html><html><body>logged out</body></html>"))
+}
@@ -0,0 +1,147 @@
+package logout
+
+// reference: https://openid.net/specs/openid-connect-session-1_0.html
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-zoox/cookie"
+	"github.com/go-zoox/logger"
+	"github.com/go-zoox/oauth2"
+)
+
+// SessionStateCookieKey is the cookie key of the session_state.
+const SessionStateCookieKey = "go-zoox_oauth2_session_state"
+
+// SessionChangedMessage is posted to the parent window when the session at provider is changed.
+const SessionChangedMessage = "oauth2:session_changed"
+
+// SaveSessionState saves the session_state of the authorization response in cookie,
+// call it in the login callback.
+func SaveSessionState(w http.ResponseWriter, r *http.Request, sessionState string) {
+	if sessionState == "" {
+		return
+	}
+
+	cookie.New(w, r).Set(SessionStateCookieKey, sessionState, &cookie.Config{
+		MaxAge: 7 * 24 * time.Hour,
+	})
+}
+
+// SessionIframeConfig is the config of the session management RP iframe.
+type SessionIframeConfig struct {
+	ClientID string
+	// Issuer is used to discover check_session_iframe when CheckSessionIframe is empty.
+	Issuer             string
+	CheckSessionIframe string
+	// Interval is how often the session is checked, default: 5 seconds.
+	Interval time.Duration
+	// SessionState returns the session_state of the request, default: the session_state cookie.
+	SessionState func(r *http.Request) string
+}
+
+// SessionIframeHandler serves the RP iframe of OpenID Connect session management,
+// which polls the OP iframe and posts SessionChangedMessage to the parent window
+// when the session at the provider has changed, such as logged out.
+type SessionIframeHandler struct {
+	cfg *SessionIframeConfig
+	//
+	opOrigin string
+}
+
+// NewSessionIframeHandler creates the RP iframe handler.
+func NewSessionIframeHandler(cfg *SessionIframeConfig) (*SessionIframeHandler, error) {
+	if cfg.ClientID == "" {
+		return nil, errors.New("oauth2: session management client id is required")
+	}
+
+	if cfg.CheckSessionIframe == "" {
+		metadata, err := oauth2.Discover(cfg.Issuer)
+		if err != nil {
+			return nil, err
+		}
+
+		if metadata.CheckSessionIframe == "" {
+			return nil, errors.New("oauth2: provider does not support session management, check_session_iframe is empty")
+		}
+
+		cfg.CheckSessionIframe = metadata.CheckSessionIframe
+	}
+
+	u, err := url.Parse(cfg.CheckSessionIframe)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("oauth2: invalid check_session_iframe " + cfg.CheckSessionIframe)
+	}
+
+	if cfg.Interval == 0 {
+		cfg.Interval = 5 * time.Second
+	}
+
+	if cfg.SessionState == nil {
+		cfg.SessionState = func(r *http.Request) string {
+			return cookie.New(nil, r).Get(SessionStateCookieKey)
+		}
+	}
+
+	return &SessionIframeHandler{
+		cfg:      cfg,
+		opOrigin: u.Scheme + "://" + u.Host,
+	}, nil
+}
+
+// ServeHTTP renders the RP iframe.
+func (h *SessionIframeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	err := sessionIframeTemplate.Execute(w, map[string]any{
+		"CheckSessionIframe": h.cfg.CheckSessionIframe,
+		"OPOrigin":           h.opOrigin,
+		"Message":            h.cfg.ClientID + " " + h.cfg.SessionState(r),
+		"Interval":           h.cfg.Interval.Milliseconds(),
+		"ChangedMessage":     SessionChangedMessage,
+	})
+	if err != nil {
+		logger.Errorf("[oauth2][session management] failed to render rp iframe: %s", err)
+	}
+}
+
+var sessionIframeTemplate = template.Must(template.New("rp-iframe").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<iframe id="op" src="{{.CheckSessionIframe}}" style="display:none"></iframe>
+<script>
+(function () {
+  var opOrigin = {{.OPOrigin}};
+  var message = {{.Message}};
+  var op = document.getElementById("op");
+  var timer = null;
+
+  function check() {
+    op.contentWindow.postMessage(message, opOrigin);
+  }
+
+  window.addEventListener("message", function (e) {
+    if (e.origin !== opOrigin) {
+      return;
+    }
+
+    if (e.data === "changed") {
+      clearInterval(timer);
+      window.parent.postMessage({{.ChangedMessage}}, window.location.origin);
+    }
+  }, false);
+
+  op.addEventListener("load", function () {
+    check();
+    timer = setInterval(check, {{.Interval}});
+  });
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,154 @@
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// DefaultIDTokenLeeway is the default clock skew allowed when validating the id_token times.
+const DefaultIDTokenLeeway = time.Minute
+
+// ErrIDTokenEmpty is returned when the token response has no id_token.
+var ErrIDTokenEmpty = errors.New("oauth2: id_token is empty")
+
+// IDTokenVerifier verifies the OpenID Connect id_token:
+// the signature, iss, aud, azp, exp, nbf, iat and nonce.
+type IDTokenVerifier struct {
+	// Issuers are the allowed iss.
+	Issuers  []string
+	ClientID string
+	KeySet   jwt.KeySet
+	// Nonce is the nonce of the authorize request, checked when not empty, see Token.Nonce.
+	Nonce string
+	// Leeway is the allowed clock skew, default: DefaultIDTokenLeeway.
+	Leeway time.Duration
+	// Now returns the current time, default: time.Now.
+	Now func() time.Time
+}
+
+// IDTokenVerifier creates the verifier of the config, the key set is discovered by the issuer.
+func (oac *Config) IDTokenVerifier() (*IDTokenVerifier, error) {
+	metadata, err := Discover(oac.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet, err := metadata.KeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &IDTokenVerifier{
+		Issuers:  []string{metadata.Issuer},
+		ClientID: oac.ClientID,
+		KeySet:   keySet,
+	}, nil
+}
+
+// VerifyIDToken verifies the id_token of the token by the verifier of the config.
+func VerifyIDToken(config *Config, token *Token) (jwt.Claims, error) {
+	if token == nil || token.IDToken == "" {
+		return nil, ErrIDTokenEmpty
+	}
+
+	verifier, err := config.IDTokenVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier.Nonce = token.Nonce()
+	return verifier.Verify(token.IDToken)
+}
+
+// Verify verifies the raw id_token and returns its claims.
+func (v *IDTokenVerifier) Verify(raw string) (jwt.Claims, error) {
+	if raw == "" {
+		return nil, ErrIDTokenEmpty
+	}
+
+	token, err := jwt.Verify(raw, v.KeySet)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: invalid id_token: %s", err)
+	}
+
+	claims := token.Claims
+	if iss := claims.String("iss"); !contains(v.Issuers, iss) {
+		return nil, fmt.Errorf("oauth2: invalid id_token issuer %s", iss)
+	}
+
+	if !claims.HasAudience(v.ClientID) {
+		return nil, fmt.Errorf("oauth2: invalid id_token audience, %s is not in %v", v.ClientID, claims.Audience())
+	}
+
+	if azp := claims.String("azp"); azp != "" && azp != v.ClientID {
+		return nil, fmt.Errorf("oauth2: invalid id_token authorized party %s", azp)
+	}
+
+	leeway := v.Leeway
+	if leeway == 0 {
+		leeway = DefaultIDTokenLeeway
+	}
+
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	if claims.Time("exp").IsZero() {
+		return nil, fmt.Errorf("oauth2: invalid id_token, exp is required")
+	}
+
+	if err := claims.ValidateTime(now, leeway); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid id_token: %s", err)
+	}
+
+	if iat := claims.Time("iat"); !iat.IsZero() && now.Add(leeway).Before(iat) {
+		return nil, fmt.Errorf("oauth2: invalid id_token, issued in the future")
+	}
+
+	if v.Nonce != "" && subtle.ConstantTimeCompare([]byte(claims.String("nonce")), []byte(v.Nonce)) != 1 {
+		return nil, fmt.Errorf("oauth2: invalid id_token nonce")
+	}
+
+	return claims, nil
+}
+
+// newNonce generates a random nonce of the authorize request.
+func newNonce() (string, error) {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("oauth2: failed to generate nonce: %s", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// isOpenIDRequest reports whether the authorize request has the openid scope, which is sent with a nonce,
+// the scope is openid by default unless the login url is custom, see generateLoginURL.
+func (oac *Config) isOpenIDRequest(opts *Options) bool {
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = parseScopes(oac.Scope)
+	}
+	if len(scopes) == 0 {
+		return oac.GetLoginURL == nil
+	}
+
+	return contains(scopes, "openid")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-zoox/fetch"
+)
+
+// KeySet provides the keys to verify the signature of jwt.
+type KeySet interface {
+	Key(kid, alg string) (any, error)
+}
+
+// JSONWebKey is a JSON Web Key (RFC 7517), only the public key members are supported.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	// rsa
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// ec
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a JSON Web Key Set.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKey returns the *rsa.PublicKey or *ecdsa.PublicKey of the jwk.
+func (k *JSONWebKey) PublicKey() (any, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := bigIntFromSegment(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid rsa key(%s) modulus: %s", k.KeyID, err)
+		}
+
+		e, err := bigIntFromSegment(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid rsa key(%s) exponent: %s", k.KeyID, err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwt: unsupported ec curve %s", k.Curve)
+		}
+
+		x, err := bigIntFromSegment(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid ec key(%s) x: %s", k.KeyID, err)
+		}
+
+		y, err := bigIntFromSegment(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid ec key(%s) y: %s", k.KeyID, err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type %s", k.KeyType)
+	}
+}
+
+// Key returns the public key by kid and alg.
+func (s *JSONWebKeySet) Key(kid, alg string) (any, error) {
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if kid != "" && key.KeyID != kid {
+			continue
+		}
+
+		if key.Algorithm != "" && alg != "" && key.Algorithm != alg {
+			continue
+		}
+
+		return key.PublicKey()
+	}
+
+	return nil, fmt.Errorf("jwt: key(kid: %s, alg: %s) not found", kid, alg)
+}
+
+// StaticKeySet is a KeySet with a single key, such as a shared secret.
+type StaticKeySet struct {
+	PublicKey any
+}
+
+// Key returns the static key.
+func (s *StaticKeySet) Key(kid, alg string) (any, error) {
+	return s.PublicKey, nil
+}
+
+// RemoteKeySet is a KeySet fetched from jwks_uri,
+// which is refreshed when the kid is not found (key rotation).
+type RemoteKeySet struct {
+	URL string
+	// MinRefreshInterval limits how often the jwks is refetched, default: 1 minute
+	MinRefreshInterval time.Duration
+	//
+	sync.Mutex
+	keys      *JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySet creates a RemoteKeySet.
+func NewRemoteKeySet(url string) *RemoteKeySet {
+	return &RemoteKeySet{
+		URL: url,
+	}
+}
+
+// Key returns the public key by kid and alg.
+func (s *RemoteKeySet) Key(kid, alg string) (any, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.keys != nil {
+		if key, err := s.keys.Key(kid, alg); err == nil {
+			return key, nil
+		}
+	}
+
+	interval := s.MinRefreshInterval
+	if interval == 0 {
+		interval = time.Minute
+	}
+	if s.keys != nil && time.Since(s.fetchedAt) < interval {
+		return s.keys.Key(kid, alg)
+	}
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	return s.keys.Key(kid, alg)
+}
+
+func (s *RemoteKeySet) refresh() error {
+	response, err := fetch.Get(s.URL, &fetch.Config{
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("jwt: failed to fetch jwks(%s): %s", s.URL, err)
+	}
+
+	if !response.Ok() {
+		return fmt.Errorf("jwt: failed to fetch jwks(%s): %s", s.URL, response.Error())
+	}
+
+	keys := &JSONWebKeySet{}
+	if err := response.UnmarshalJSON(keys); err != nil {
+		return fmt.Errorf("jwt: invalid jwks(%s): %s", s.URL, err)
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
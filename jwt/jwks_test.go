@@ -0,0 +1,174 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, JSONWebKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, JSONWebKey{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: RS256,
+		N:         encodeSegment(key.PublicKey.N.Bytes()),
+		E:         encodeSegment(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+	}
+}
+
+func TestJSONWebKeySetKey(t *testing.T) {
+	rsaKey, rsaJWK := rsaJWK(t, "rsa")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryption := rsaJWK
+	encryption.KeyID = "enc"
+	encryption.Use = "enc"
+
+	keys := &JSONWebKeySet{
+		Keys: []JSONWebKey{
+			rsaJWK,
+			encryption,
+			{
+				KeyType: "EC",
+				KeyID:   "ec",
+				Curve:   "P-256",
+				X:       encodeSegment(ecKey.PublicKey.X.Bytes()),
+				Y:       encodeSegment(ecKey.PublicKey.Y.Bytes()),
+			},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		kid     string
+		alg     string
+		want    any
+		wantErr bool
+	}{
+		{name: "rsa by kid", kid: "rsa", alg: RS256, want: &rsaKey.PublicKey},
+		{name: "ec by kid", kid: "ec", alg: ES256, want: &ecKey.PublicKey},
+		{name: "first key without kid", alg: RS256, want: &rsaKey.PublicKey},
+		{name: "unknown kid", kid: "unknown", alg: RS256, wantErr: true},
+		{name: "alg mismatch", kid: "rsa", alg: RS512, wantErr: true},
+		{name: "encryption key", kid: "enc", alg: RS256, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			key, err := keys.Key(c.kid, c.alg)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %T", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch want := c.want.(type) {
+			case *rsa.PublicKey:
+				if !want.Equal(key) {
+					t.Error("unexpected rsa key")
+				}
+			case *ecdsa.PublicKey:
+				if !want.Equal(key) {
+					t.Error("unexpected ec key")
+				}
+			}
+		})
+	}
+}
+
+func TestJSONWebKeyPublicKeyErrors(t *testing.T) {
+	cases := []JSONWebKey{
+		{KeyType: "oct"},
+		{KeyType: "EC", Curve: "P-192"},
+		{KeyType: "RSA", N: "!", E: "AQAB"},
+	}
+
+	for _, key := range cases {
+		if _, err := key.PublicKey(); err == nil {
+			t.Errorf("PublicKey(%+v) expected error", key)
+		}
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	key1, jwk1 := rsaJWK(t, "k1")
+	key2, jwk2 := rsaJWK(t, "k2")
+
+	var mu sync.Mutex
+	keys := []JSONWebKey{jwk1}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&JSONWebKeySet{Keys: keys})
+	}))
+	defer server.Close()
+
+	keySet := NewRemoteKeySet(server.URL)
+	keySet.MinRefreshInterval = time.Hour
+
+	sign := func(kid string, key *rsa.PrivateKey) string {
+		raw, err := Sign(&Header{Algorithm: RS256, KeyID: kid}, Claims{"sub": "user"}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	if _, err := Verify(sign("k1", key1), keySet); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(sign("k1", key1), keySet); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 (cached)", fetches)
+	}
+
+	// k2 is not published yet, the refetch is limited by MinRefreshInterval
+	if _, err := Verify(sign("k2", key2), keySet); err == nil {
+		t.Fatal("unknown kid is verified")
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 (rate limited)", fetches)
+	}
+
+	// the key is rotated, an unknown kid refreshes the jwks
+	mu.Lock()
+	keys = []JSONWebKey{jwk1, jwk2}
+	mu.Unlock()
+	keySet.MinRefreshInterval = time.Nanosecond
+
+	if _, err := Verify(sign("k2", key2), keySet); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Fatalf("fetches = %d, want 2 (refreshed)", fetches)
+	}
+}
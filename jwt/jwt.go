@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+// Supported signing algorithms.
+const (
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	HS256 = "HS256"
+)
+
+// Header is the jose header of a jwt.
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Claims is the claims set of a jwt.
+type Claims map[string]any
+
+// Sign signs the claims with key and returns the compact serialization.
+//
+// key is *rsa.PrivateKey for RS*, *ecdsa.PrivateKey for ES* and []byte for HS256.
+func Sign(header *Header, claims Claims, key any) (string, error) {
+	if header == nil {
+		header = &Header{}
+	}
+	if header.Algorithm == "" {
+		header.Algorithm = RS256
+	}
+	if header.Type == "" {
+		header.Type = "JWT"
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("jwt: failed to encode header: %s", err)
+	}
+
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: failed to encode claims: %s", err)
+	}
+
+	signingInput := encodeSegment(headerBytes) + "." + encodeSegment(claimsBytes)
+	signature, err := sign(header.Algorithm, []byte(signingInput), key)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+// ParsePrivateKey parses a PEM encoded RSA or ECDSA private key (PKCS#1, PKCS#8 or SEC 1).
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt: invalid private key, no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("jwt: unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("jwt: failed to parse private key (type: %s)", block.Type)
+}
+
+// AlgorithmForKey returns the default signing algorithm for the key.
+func AlgorithmForKey(key any) string {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 384:
+			return ES384
+		case 521:
+			return ES512
+		default:
+			return ES256
+		}
+	case []byte:
+		return HS256
+	default:
+		return RS256
+	}
+}
+
+func sign(alg string, input []byte, key any) ([]byte, error) {
+	switch alg {
+	case RS256, RS384, RS512:
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: algorithm %s requires an rsa private key, got %T", alg, key)
+		}
+
+		hashFunc := hashForAlgorithm(alg)
+		return rsa.SignPKCS1v15(rand.Reader, rsaKey, hashFunc, digest(hashFunc, input))
+	case ES256, ES384, ES512:
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: algorithm %s requires an ecdsa private key, got %T", alg, key)
+		}
+
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest(hashForAlgorithm(alg), input))
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to sign: %s", err)
+		}
+
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("jwt: algorithm %s requires a []byte secret, got %T", alg, key)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %s", alg)
+	}
+}
+
+func hashForAlgorithm(alg string) crypto.Hash {
+	switch alg {
+	case RS384, ES384:
+		return crypto.SHA384
+	case RS512, ES512:
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+func digest(h crypto.Hash, input []byte) []byte {
+	var hasher hash.Hash
+	switch h {
+	case crypto.SHA384:
+		hasher = sha512.New384()
+	case crypto.SHA512:
+		hasher = sha512.New()
+	default:
+		hasher = sha256.New()
+	}
+
+	hasher.Write(input)
+	return hasher.Sum(nil)
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+func bigIntFromSegment(segment string) (*big.Int, error) {
+	data, err := decodeSegment(segment)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
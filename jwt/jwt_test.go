@@ -0,0 +1,170 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		alg    string
+		curve  elliptic.Curve
+		secret []byte
+	}{
+		{name: "RS256", alg: RS256},
+		{name: "RS384", alg: RS384},
+		{name: "RS512", alg: RS512},
+		{name: "ES256", alg: ES256, curve: elliptic.P256()},
+		{name: "ES384", alg: ES384, curve: elliptic.P384()},
+		{name: "ES512", alg: ES512, curve: elliptic.P521()},
+		{name: "HS256", alg: HS256, secret: []byte("secret")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var privateKey, publicKey any = rsaKey, &rsaKey.PublicKey
+			if c.curve != nil {
+				ecKey, err := ecdsa.GenerateKey(c.curve, rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				privateKey, publicKey = ecKey, &ecKey.PublicKey
+			}
+			if c.secret != nil {
+				privateKey, publicKey = c.secret, c.secret
+			}
+
+			if alg := AlgorithmForKey(privateKey); c.alg != RS384 && c.alg != RS512 && alg != c.alg {
+				t.Errorf("AlgorithmForKey() = %s, want %s", alg, c.alg)
+			}
+
+			raw, err := Sign(&Header{Algorithm: c.alg, KeyID: "k1"}, Claims{"sub": "user"}, privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := Verify(raw, &StaticKeySet{PublicKey: publicKey})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if token.Claims.String("sub") != "user" || token.Header.KeyID != "k1" {
+				t.Errorf("unexpected token %+v", token)
+			}
+
+			// tampered claims
+			parts := strings.Split(raw, ".")
+			tampered := parts[0] + "." + encodeSegment([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+			if _, err := Verify(tampered, &StaticKeySet{PublicKey: publicKey}); err == nil {
+				t.Error("tampered token is verified")
+			}
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := Sign(&Header{Algorithm: RS256}, Claims{"sub": "user"}, rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(signed, ".")
+
+	unsigned := func(alg string) string {
+		return encodeSegment([]byte(`{"alg":"`+alg+`","typ":"JWT"}`)) + "." + parts[1] + "."
+	}
+
+	// HS256 signed with the rsa public key bytes, the algorithm confusion attack
+	confused, err := Sign(&Header{Algorithm: HS256}, Claims{"sub": "user"}, rsaKey.PublicKey.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		raw  string
+		key  any
+	}{
+		{"alg none", unsigned("none"), &rsaKey.PublicKey},
+		{"alg none lowercase", unsigned("None"), []byte("secret")},
+		{"empty alg", unsigned(""), &rsaKey.PublicKey},
+		{"rsa token with ecdsa key", signed, &ecKey.PublicKey},
+		{"rsa token with secret", signed, []byte("secret")},
+		{"hs256 with rsa key", confused, &rsaKey.PublicKey},
+		{"hs256 with string secret", confused, string(rsaKey.PublicKey.N.Bytes())},
+		{"malformed", "a.b", &rsaKey.PublicKey},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Verify(c.raw, &StaticKeySet{PublicKey: c.key}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSignRejectsKeyMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		alg string
+		key any
+	}{
+		{ES256, rsaKey},
+		{HS256, rsaKey},
+		{RS256, []byte("secret")},
+		{"none", rsaKey},
+	}
+
+	for _, c := range cases {
+		if _, err := Sign(&Header{Algorithm: c.alg}, Claims{}, c.key); err == nil {
+			t.Errorf("Sign(%s, %T) expected error", c.alg, c.key)
+		}
+	}
+}
+
+func TestClaimsValidateTime(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cases := []struct {
+		name   string
+		claims Claims
+		want   error
+	}{
+		{"valid", Claims{"exp": float64(now.Unix() + 60), "nbf": float64(now.Unix() - 60)}, nil},
+		{"expired", Claims{"exp": float64(now.Unix() - 120)}, ErrTokenExpired},
+		{"expired within leeway", Claims{"exp": float64(now.Unix() - 30)}, nil},
+		{"not valid yet", Claims{"nbf": float64(now.Unix() + 120)}, ErrTokenNotValidYet},
+		{"no times", Claims{}, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.claims.ValidateTime(now, time.Minute); err != c.want {
+				t.Errorf("ValidateTime() = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
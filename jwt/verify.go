@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrTokenExpired is the error of token is expired.
+var ErrTokenExpired = errors.New("jwt: token is expired")
+
+// ErrTokenNotValidYet is the error of token is not valid yet.
+var ErrTokenNotValidYet = errors.New("jwt: token is not valid yet")
+
+// Token is a parsed jwt.
+type Token struct {
+	Raw       string
+	Header    Header
+	Claims    Claims
+	Signature []byte
+	//
+	signingInput string
+}
+
+// Parse parses the compact serialized jwt without verifying the signature.
+func Parse(raw string) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("jwt: malformed token, expected 3 parts, got %d", len(parts))
+	}
+
+	token := &Token{
+		Raw:          raw,
+		signingInput: parts[0] + "." + parts[1],
+	}
+
+	headerBytes, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed header: %s", err)
+	}
+	if err := json.Unmarshal(headerBytes, &token.Header); err != nil {
+		return nil, fmt.Errorf("jwt: malformed header: %s", err)
+	}
+
+	claimsBytes, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed claims: %s", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(claimsBytes)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&token.Claims); err != nil {
+		return nil, fmt.Errorf("jwt: malformed claims: %s", err)
+	}
+
+	if token.Signature, err = decodeSegment(parts[2]); err != nil {
+		return nil, fmt.Errorf("jwt: malformed signature: %s", err)
+	}
+
+	return token, nil
+}
+
+// Verify parses the jwt and verifies the signature with the key from keys.
+func Verify(raw string, keys KeySet) (*Token, error) {
+	token, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keys.Key(token.Header.KeyID, token.Header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := token.Verify(key); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Verify verifies the signature of the token with the public key (or secret for HS256).
+func (t *Token) Verify(key any) error {
+	input := []byte(t.signingInput)
+	alg := t.Header.Algorithm
+
+	switch alg {
+	case RS256, RS384, RS512:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("jwt: algorithm %s requires an rsa public key, got %T", alg, key)
+		}
+
+		hashFunc := hashForAlgorithm(alg)
+		if err := rsa.VerifyPKCS1v15(publicKey, hashFunc, digest(hashFunc, input), t.Signature); err != nil {
+			return fmt.Errorf("jwt: invalid signature: %s", err)
+		}
+		return nil
+	case ES256, ES384, ES512:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("jwt: algorithm %s requires an ecdsa public key, got %T", alg, key)
+		}
+
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(t.Signature) != 2*size {
+			return errors.New("jwt: invalid signature length")
+		}
+
+		r := new(big.Int).SetBytes(t.Signature[:size])
+		s := new(big.Int).SetBytes(t.Signature[size:])
+		if !ecdsa.Verify(publicKey, digest(hashForAlgorithm(alg), input), r, s) {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("jwt: algorithm %s requires a []byte secret, got %T", alg, key)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		if !hmac.Equal(mac.Sum(nil), t.Signature) {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	default:
+		// alg none is never accepted
+		return fmt.Errorf("jwt: unsupported algorithm %s", alg)
+	}
+}
+
+// Has reports whether the claim exists.
+func (c Claims) Has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+// String returns the string claim.
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns the claim as strings, both string and array of strings are supported.
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := []string{}
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Audience returns the aud claim.
+func (c Claims) Audience() []string {
+	return c.Strings("aud")
+}
+
+// HasAudience reports whether the aud claim contains audience.
+func (c Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience() {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Time returns the NumericDate claim, such as exp, iat and nbf.
+func (c Claims) Time(name string) time.Time {
+	var seconds float64
+	switch value := c[name].(type) {
+	case json.Number:
+		seconds, _ = value.Float64()
+	case float64:
+		seconds = value
+	case int64:
+		seconds = float64(value)
+	case int:
+		seconds = float64(value)
+	default:
+		return time.Time{}
+	}
+
+	return time.Unix(int64(seconds), 0)
+}
+
+// ValidateTime validates the exp and nbf claims with the leeway.
+func (c Claims) ValidateTime(now time.Time, leeway time.Duration) error {
+	if exp := c.Time("exp"); !exp.IsZero() && now.After(exp.Add(leeway)) {
+		return ErrTokenExpired
+	}
+
+	if nbf := c.Time("nbf"); !nbf.IsZero() && now.Add(leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+
+	return nil
+}
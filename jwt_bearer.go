@@ -0,0 +1,213 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/logger"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// GrantTypeJWTBearer is the grant type of JWT bearer assertion, see RFC 7523.
+const GrantTypeJWTBearer = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// JWTBearerConfig is the config of the JWT bearer assertion grant (RFC 7523).
+type JWTBearerConfig struct {
+	TokenURL string
+	// Issuer is the iss claim of the assertion, usually the client id or service account email.
+	Issuer string
+	// Subject is the sub claim of the assertion, default: Issuer.
+	Subject string
+	// Audience is the aud claim of the assertion, default: TokenURL.
+	Audience string
+	Scope    string
+
+	// PrivateKey is the PEM encoded private key used to sign the assertion.
+	PrivateKey []byte
+	// PrivateKeyFile is the path of PEM encoded private key, used when PrivateKey is empty.
+	PrivateKeyFile string
+	// PrivateKeyID is the kid header of the assertion.
+	PrivateKeyID string
+	// Algorithm is the signing algorithm, default: RS256 for rsa keys and ES256 for ec keys.
+	Algorithm string
+
+	// Expires is the lifetime of the assertion, default: 1 hour.
+	Expires time.Duration
+	// Claims are the extra claims of the assertion.
+	Claims map[string]any
+}
+
+// googleServiceAccount is the google service account credentials json.
+type googleServiceAccount struct {
+	Type         string `json:"type"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// JWTBearerConfigFromGoogleCredentials creates the JWT bearer config from google service account json.
+func JWTBearerConfigFromGoogleCredentials(data []byte, scope string) (*JWTBearerConfig, error) {
+	var account googleServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid google credentials: %s", err)
+	}
+
+	if account.Type != "service_account" {
+		return nil, fmt.Errorf("oauth2: unsupported google credentials type(%s)", account.Type)
+	}
+
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = "https://oauth2.googleapis.com/token"
+	}
+
+	return &JWTBearerConfig{
+		TokenURL:     tokenURL,
+		Issuer:       account.ClientEmail,
+		Scope:        scope,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+	}, nil
+}
+
+// JWTBearerConfigFromGoogleCredentialsFile creates the JWT bearer config from google service account json file.
+func JWTBearerConfigFromGoogleCredentialsFile(path string, scope string) (*JWTBearerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read google credentials file(%s): %s", path, err)
+	}
+
+	return JWTBearerConfigFromGoogleCredentials(data, scope)
+}
+
+// Assertion builds and signs the JWT assertion.
+func (c *JWTBearerConfig) Assertion() (string, error) {
+	privateKey := c.PrivateKey
+	if len(privateKey) == 0 && c.PrivateKeyFile != "" {
+		data, err := os.ReadFile(c.PrivateKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("oauth2: failed to read private key file(%s): %s", c.PrivateKeyFile, err)
+		}
+
+		privateKey = data
+	}
+	if len(privateKey) == 0 {
+		return "", errors.New("oauth2: jwt bearer private key is empty")
+	}
+
+	key, err := jwt.ParsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	subject := c.Subject
+	if subject == "" {
+		subject = c.Issuer
+	}
+
+	audience := c.Audience
+	if audience == "" {
+		audience = c.TokenURL
+	}
+
+	expires := c.Expires
+	if expires == 0 {
+		expires = time.Hour
+	}
+
+	algorithm := c.Algorithm
+	if algorithm == "" {
+		algorithm = jwt.AlgorithmForKey(key)
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{}
+	for k, v := range c.Claims {
+		claims[k] = v
+	}
+	claims["iss"] = c.Issuer
+	claims["sub"] = subject
+	claims["aud"] = audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(expires).Unix()
+	if c.Scope != "" {
+		// google service accounts read the scope from the assertion
+		claims["scope"] = c.Scope
+	}
+
+	return jwt.Sign(&jwt.Header{
+		Algorithm: algorithm,
+		KeyID:     c.PrivateKeyID,
+	}, claims, key)
+}
+
+// Token exchanges a new signed assertion for a token.
+func (c *JWTBearerConfig) Token() (*Token, error) {
+	if c.TokenURL == "" {
+		return nil, ErrConfigTokenURLEmpty
+	}
+
+	assertion, err := c.Assertion()
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]string{
+		"grant_type": GrantTypeJWTBearer,
+		"assertion":  assertion,
+	}
+	if c.Scope != "" {
+		body["scope"] = c.Scope
+	}
+
+	response, err := fetch.Post(c.TokenURL, &fetch.Config{
+		Headers: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Accept":       "application/json",
+		},
+		Body: body,
+	})
+	if err != nil {
+		return nil, errors.New("get access token error by jwt bearer assertion: " + err.Error())
+	}
+
+	logger.Debugf("[oauth2][JWTBearer][token]: %s", response.String())
+
+	if tokenErr := parseTokenError(response); tokenErr != nil {
+		return nil, tokenErr
+	}
+
+	if !response.Ok() {
+		return nil, fmt.Errorf("get access token error by jwt bearer assertion: %s", response.Error())
+	}
+
+	token := &Token{
+		AccessToken: response.Get("access_token").String(),
+		ExpiresIn:   response.Get("expires_in").Int(),
+		TokenType:   response.Get("token_type").String(),
+		raw:         response,
+	}
+	token.Expiry = expiryFromExpiresIn(token.ExpiresIn)
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("get access token error by jwt bearer assertion: access_token is empty, response: %s", response.String())
+	}
+
+	return token, nil
+}
+
+// TokenSource returns a TokenSource which caches the token until it expires.
+func (c *JWTBearerConfig) TokenSource() TokenSource {
+	return ReuseTokenSource(nil, TokenSourceFunc(c.Token))
+}
+
+// Client returns a http client authenticated with the tokens of the jwt bearer grant.
+func (c *JWTBearerConfig) Client() *http.Client {
+	return NewHTTPClient(c.TokenSource())
+}
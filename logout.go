@@ -0,0 +1,115 @@
+package oauth2
+
+import (
+	"net/url"
+
+	"github.com/go-zoox/logger"
+)
+
+// LogoutRequest is the RP-initiated logout request of OpenID Connect.
+type LogoutRequest struct {
+	State                 string
+	IDTokenHint           string
+	PostLogoutRedirectURI string
+	LogoutHint            string
+	UILocales             string
+	// Params are the other extra parameters.
+	Params url.Values
+}
+
+// WithIDTokenHint sets the id_token_hint parameter of the logout request.
+func WithIDTokenHint(idToken string) Option {
+	return WithParam("id_token_hint", idToken)
+}
+
+// WithPostLogoutRedirectURI sets the post_logout_redirect_uri parameter of the logout request,
+// overrides Config.PostLogoutRedirectURI.
+func WithPostLogoutRedirectURI(uri string) Option {
+	return WithParam("post_logout_redirect_uri", uri)
+}
+
+// WithLogoutHint sets the logout_hint parameter of the logout request.
+func WithLogoutHint(hint string) Option {
+	return WithParam("logout_hint", hint)
+}
+
+// newLogoutRequest creates the logout request from the state and options.
+func (oac *Config) newLogoutRequest(state string, opts *Options) *LogoutRequest {
+	params := url.Values{}
+	for key, values := range opts.Params {
+		params[key] = values
+	}
+
+	pop := func(key string) string {
+		value := params.Get(key)
+		params.Del(key)
+		return value
+	}
+
+	req := &LogoutRequest{
+		State:                 state,
+		IDTokenHint:           pop("id_token_hint"),
+		PostLogoutRedirectURI: pop("post_logout_redirect_uri"),
+		LogoutHint:            pop("logout_hint"),
+		UILocales:             pop("ui_locales"),
+		Params:                params,
+	}
+
+	if req.PostLogoutRedirectURI == "" {
+		req.PostLogoutRedirectURI = oac.PostLogoutRedirectURI
+	}
+
+	return req
+}
+
+// endSessionEndpoint returns LogoutURL or the end_session_endpoint from discovery.
+func (oac *Config) endSessionEndpoint() string {
+	if oac.LogoutURL != "" || oac.Issuer == "" {
+		return oac.LogoutURL
+	}
+
+	metadata, err := Discover(oac.Issuer)
+	if err != nil {
+		logger.Errorf("[oauth2][logout] %s", err)
+		return ""
+	}
+
+	return metadata.EndSessionEndpoint
+}
+
+// generateLogoutURL gets the logout url.
+//
+// Example: https://login.example.com/logout?client_id=CLIENT_ID&id_token_hint=ID_TOKEN&post_logout_redirect_uri=https%3A%2F%2Fabc.com&state=anything
+func (oac *Config) generateLogoutURL(state string, opts *Options) string {
+	req := oac.newLogoutRequest(state, opts)
+	if oac.GetEndSessionURL != nil {
+		return oac.GetEndSessionURL(oac, req)
+	}
+
+	if oac.GetLogoutURL != nil {
+		return oac.GetLogoutURL(oac)
+	}
+
+	endpoint := oac.endSessionEndpoint()
+	if endpoint == "" {
+		return ""
+	}
+
+	params := url.Values{}
+	for key, values := range req.Params {
+		params[key] = values
+	}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	set("client_id", oac.ClientID)
+	set("id_token_hint", req.IDTokenHint)
+	set("post_logout_redirect_uri", req.PostLogoutRedirectURI)
+	set("state", req.State)
+	set("logout_hint", req.LogoutHint)
+	set("ui_locales", req.UILocales)
+
+	return appendQuery(endpoint, params)
+}
@@ -0,0 +1,153 @@
+package microsoft
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-zoox/core-utils/safe"
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/oauth2"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// graphURL is the Microsoft Graph api.
+var graphURL = "https://graph.microsoft.com/v1.0"
+
+// keySet is the signing keys shared by all the tenants.
+var keySet = jwt.NewRemoteKeySet(LoginURL + "/common/discovery/v2.0/keys")
+
+// tenantIDs caches the tenant id of the tenant domains.
+var tenantIDs = safe.NewMap[string, string]()
+
+// errGraphForbidden is returned when the access token is not allowed to call the Graph api.
+var errGraphForbidden = errors.New("oauth2: microsoft graph access is forbidden")
+
+// verifyIDToken verifies the id_token, the iss must be the issuer of its tid allowed by the tenant.
+func verifyIDToken(config *oauth2.Config, tenant string, token *oauth2.Token) (jwt.Claims, error) {
+	parsed, err := jwt.Parse(token.IDToken)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: invalid id_token: %s", err)
+	}
+
+	tid := parsed.Claims.String("tid")
+	switch tenant {
+	case TenantCommon:
+	case TenantOrganizations:
+		if tid == consumersTenantID {
+			return nil, fmt.Errorf("%w, personal accounts are not allowed", oauth2.ErrUserAccessDenied)
+		}
+	case TenantConsumers:
+		if tid != consumersTenantID {
+			return nil, fmt.Errorf("%w, only personal accounts are allowed", oauth2.ErrUserAccessDenied)
+		}
+	default:
+		expected, err := tenantID(tenant)
+		if err != nil {
+			return nil, err
+		}
+
+		if tid != expected {
+			return nil, fmt.Errorf("%w, tenant(%s) is not allowed", oauth2.ErrUserAccessDenied, tid)
+		}
+	}
+
+	verifier := &oauth2.IDTokenVerifier{
+		Issuers:  []string{fmt.Sprintf("%s/%s/v2.0", LoginURL, tid)},
+		ClientID: config.ClientID,
+		KeySet:   keySet,
+		Nonce:    token.Nonce(),
+	}
+
+	return verifier.Verify(token.IDToken)
+}
+
+// tenantID resolves the tenant id of the tenant domain by its discovery document.
+func tenantID(tenant string) (string, error) {
+	if isTenantID(tenant) {
+		return strings.ToLower(tenant), nil
+	}
+
+	if tenantIDs.Has(tenant) {
+		return tenantIDs.Get(tenant), nil
+	}
+
+	response, err := fetch.Get(fmt.Sprintf("%s/%s/v2.0/.well-known/openid-configuration", LoginURL, tenant))
+	if err != nil {
+		return "", fmt.Errorf("oauth2: failed to discover microsoft tenant(%s): %s", tenant, err)
+	}
+
+	if response.Status != 200 {
+		return "", fmt.Errorf("oauth2: failed to discover microsoft tenant(%s): (status: %d) %s", tenant, response.Status, response.Get("error_description").String())
+	}
+
+	// issuer: https://login.microsoftonline.com/{tenant id}/v2.0
+	issuer := strings.TrimSuffix(response.Get("issuer").String(), "/v2.0")
+	id := issuer[strings.LastIndex(issuer, "/")+1:]
+	if !isTenantID(id) {
+		return "", fmt.Errorf("oauth2: invalid issuer of microsoft tenant(%s): %s", tenant, response.Get("issuer").String())
+	}
+
+	tenantIDs.Set(tenant, id)
+	return id, nil
+}
+
+// memberGroups gets the group ids of the user from Graph, which is used for the groups overage,
+// GroupMemberScope is required, errGraphForbidden otherwise.
+func memberGroups(token *oauth2.Token) ([]string, error) {
+	response, err := fetch.Post(graphURL+"/me/getMemberGroups", &fetch.Config{
+		Headers: map[string]string{
+			"Authorization": "Bearer " + token.AccessToken,
+			"Content-Type":  "application/json",
+		},
+		Body: map[string]any{
+			"securityEnabledOnly": false,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to get microsoft member groups: %s", err)
+	}
+
+	if response.Status == 401 || response.Status == 403 {
+		return nil, fmt.Errorf("%w: (status: %d) %s", errGraphForbidden, response.Status, response.Get("error.message").String())
+	}
+
+	if response.Status != 200 {
+		return nil, fmt.Errorf("oauth2: failed to get microsoft member groups: (status: %d) %s", response.Status, response.Get("error.message").String())
+	}
+
+	groups := []string{}
+	for _, group := range response.Get("value").Array() {
+		groups = append(groups, group.String())
+	}
+
+	return groups, nil
+}
+
+// profilePhoto gets the profile photo as data url, empty if the user has no photo.
+func profilePhoto(token *oauth2.Token) (string, error) {
+	response, err := fetch.Get(graphURL+"/me/photos/96x96/$value", &fetch.Config{
+		Headers: map[string]string{
+			"Authorization": "Bearer " + token.AccessToken,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("oauth2: failed to get microsoft profile photo: %s", err)
+	}
+
+	if response.Status == 404 {
+		return "", nil
+	}
+
+	if response.Status != 200 {
+		return "", fmt.Errorf("oauth2: failed to get microsoft profile photo: (status: %d) %s", response.Status, response.Get("error.message").String())
+	}
+
+	contentType := response.Headers.Get("Content-Type")
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(response.Body), nil
+}
@@ -1,15 +1,48 @@
 package microsoft
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-zoox/logger"
 	"github.com/go-zoox/oauth2"
 )
 
+// Tenants of Microsoft identity platform, or a tenant id or domain, such as contoso.onmicrosoft.com.
+const (
+	// TenantCommon allows both work or school accounts and personal accounts.
+	TenantCommon = "common"
+	// TenantOrganizations allows work or school accounts only.
+	TenantOrganizations = "organizations"
+	// TenantConsumers allows personal accounts only.
+	TenantConsumers = "consumers"
+)
+
+// consumersTenantID is the tenant id of the personal accounts.
+const consumersTenantID = "9188040d-6c67-4c5b-b112-36a304b66dad"
+
+// LoginURL is the url of Microsoft identity platform.
+const LoginURL = "https://login.microsoftonline.com"
+
+// GroupMemberScope is the scope to get the groups from Graph when the id_token has too many groups (overage),
+// which requires admin consent, the users of overage login without groups if it is not granted.
+const GroupMemberScope = "GroupMember.Read.All"
+
 type MicrosoftConfig struct {
 	// config.Config
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
 	Scope        string `json:"scope"`
+	//
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri"`
+	//
+	// Tenant is common (default), organizations, consumers, or a tenant id or domain,
+	// the iss of the id_token is validated against it.
+	Tenant string `json:"tenant"`
+	// FetchPhoto sets User.Avatar to the data url of the profile photo from Graph.
+	FetchPhoto bool `json:"fetch_photo"`
 }
 
 func New(cfg *MicrosoftConfig) (oauth2.Client, error) {
@@ -18,17 +51,24 @@ func New(cfg *MicrosoftConfig) (oauth2.Client, error) {
 		scope = "openid offline_access user.read"
 	}
 
+	tenant := cfg.Tenant
+	if tenant == "" {
+		tenant = TenantCommon
+	}
+
 	config := oauth2.Config{
-		Name:        "Microsoft",
-		AuthURL:     "https://login.microsoftonline.com/common/oauth2/v2.0/authorize",
-		TokenURL:    "https://login.microsoftonline.com/common/oauth2/v2.0/token",
-		UserInfoURL: "https://graph.microsoft.com/v1.0/me",
-		// LogoutURL:    "https://login.microsoftonline.com/logout",
+		Name:         "Microsoft",
+		AuthURL:      fmt.Sprintf("%s/%s/oauth2/v2.0/authorize", LoginURL, tenant),
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", LoginURL, tenant),
+		UserInfoURL:  graphURL + "/me",
+		LogoutURL:    fmt.Sprintf("%s/%s/oauth2/v2.0/logout", LoginURL, tenant),
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
 		//
+		PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
+		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
 		ExpiresInAttributeName:    "expires_in",
@@ -39,7 +79,82 @@ func New(cfg *MicrosoftConfig) (oauth2.Client, error) {
 		NicknameAttributeName: "displayName",
 		// AvatarAttributeName:   "picture",
 		// HomepageAttributeName: "web_url",
+		GivenNameAttributeName:  "givenName",
+		FamilyNameAttributeName: "surname",
+		LocaleAttributeName:     "preferredLanguage",
+		PhoneAttributeName:      "mobilePhone",
+		//
+		ClaimMappings: map[string]*oauth2.ClaimMapping{
+			// mail is often null for personal accounts
+			"email": {Paths: []string{"mail", "userPrincipalName"}},
+		},
+	}
+
+	config.EnrichUser = func(oac *oauth2.Config, token *oauth2.Token, user *oauth2.User) error {
+		if token.IDToken != "" {
+			claims, err := verifyIDToken(oac, tenant, token)
+			if err != nil {
+				return err
+			}
+
+			// the groups are omitted from the id_token when the user has too many groups (overage)
+			groups := claims.Strings("groups")
+			if names, ok := claims["_claim_names"].(map[string]any); ok && names["groups"] != nil {
+				if groups, err = memberGroups(token); err != nil {
+					if !errors.Is(err, errGraphForbidden) {
+						return err
+					}
+
+					logger.Warnf("[oauth2][microsoft] groups of user(%s) are omitted, add %s to the scope: %s", user.ID, GroupMemberScope, err)
+				}
+			}
+
+			user.Groups = append(user.Groups, groups...)
+			// the app roles assigned to the user
+			user.Permissions = append(user.Permissions, claims.Strings("roles")...)
+		}
+
+		if cfg.FetchPhoto {
+			photo, err := profilePhoto(token)
+			if err != nil {
+				return err
+			}
+
+			if photo != "" {
+				user.Avatar = photo
+			}
+		}
+
+		return nil
 	}
 
 	return oauth2.New(config)
 }
+
+// isTenantID reports whether the tenant is a tenant id, such as 72f988bf-86f1-41af-91ab-2d7cd011db47.
+func isTenantID(tenant string) bool {
+	return len(tenant) == 36 && strings.Count(tenant, "-") == 4
+}
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "microsoft",
+		DisplayName:    "Microsoft",
+		Icon:           "microsoft",
+		DefaultScopes:  []string{"openid", "offline_access", "user.read"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		// tenant and fetch_photo
+		config := &MicrosoftConfig{}
+		if err := oauth2.DecodeProviderOptions(cfg, config); err != nil {
+			return nil, err
+		}
+
+		config.ClientID = cfg.ClientID
+		config.ClientSecret = cfg.ClientSecret
+		config.RedirectURI = cfg.RedirectURI
+		config.Scope = cfg.Scope
+		config.PostLogoutRedirectURI = cfg.PostLogoutRedirectURI
+		return New(config)
+	})
+}
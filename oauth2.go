@@ -2,22 +2,54 @@ package oauth2
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-zoox/logger"
 )
 
+// LoginStateTTL is how long the config of an authorize request is kept for its callback,
+// so that the in-flight logins complete with the config they started with after Update.
+var LoginStateTTL = 10 * time.Minute
+
+// ErrLoginStateNotFound is returned by the OpenID Connect callback when the login state is unknown or expired,
+// since the id_token cannot be bound to the login without its nonce,
+// the login states are kept in process, so the callback must reach the instance the login started on.
+var ErrLoginStateNotFound = errors.New("oauth2: login state is unknown or expired")
+
 // Client is the oauth2 client interface.
 type Client interface {
-	Authorize(state string, callback func(loginUrl string))
-	Callback(code, state string, cb func(user *User, token *Token, err error))
-	Logout(state string, callback func(logoutUrl string))
+	Authorize(state string, callback func(loginUrl string), options ...Option)
+	Upgrade(state string, scopes []string, callback func(loginUrl string), options ...Option)
+	Callback(code, state string, cb func(user *User, token *Token, err error), options ...Option)
+	Logout(state string, callback func(logoutUrl string), options ...Option)
 	Register(callback func(registerUrl string))
 	//
-	RefreshToken(refreshToken string) (*Token, error)
+	RefreshToken(refreshToken string, options ...Option) (*Token, error)
+	//
+	Config() *Config
+	Update(config Config) error
 }
 
 // client is the OAuth2 client.
 type client struct {
-	Config
 	StepCallback
+	//
+	config atomic.Pointer[Config]
+	// states is the config of the in-flight logins by state.
+	states   sync.Map
+	prunedAt atomic.Int64
+}
+
+type loginState struct {
+	config *Config
+	// scopes are the requested scopes, which are granted if the token response omits the scope.
+	scopes []string
+	// nonce is the nonce of the authorize request, which is expected in the id_token.
+	nonce     string
+	expiresAt time.Time
 }
 
 // New creates a OAuth2 client.
@@ -30,34 +62,106 @@ func New(config Config, options ...interface{}) (Client, error) {
 		return nil, err
 	}
 
-	return &client{
-		Config: config,
-	}, nil
+	oa := &client{}
+	oa.config.Store(&config)
+	return oa, nil
+}
+
+// Config returns a copy of the current config.
+func (oa *client) Config() *Config {
+	config := *oa.config.Load()
+	return &config
+}
+
+// Update validates and swaps the config atomically, such as rotated client secret or endpoints,
+// the logins authorized before complete with the previous config.
+func (oa *client) Update(config Config) error {
+	if err := ValidateConfig(&config); err != nil {
+		return err
+	}
+
+	if err := ApplyDefaultConfig(&config); err != nil {
+		return err
+	}
+
+	oa.config.Store(&config)
+	return nil
 }
 
 // Authorize is the first step of login
-// means redirect to oauth server authorize page
-func (oa *client) Authorize(state string, callback func(loginUrl string)) {
-	callback(oa.generateLoginURL(state))
+// means redirect to oauth server authorize page,
+// the callback gets the error page url if the authorize request is invalid, such as authorization_details without type.
+func (oa *client) Authorize(state string, callback func(loginUrl string), options ...Option) {
+	config := oa.config.Load()
+	opts := applyOptions(options)
+	// the nonce binds the id_token to the login, which is checked by IDTokenVerifier
+	if state != "" && config.isOpenIDRequest(opts) && opts.Params.Get("nonce") == "" {
+		nonce, err := newNonce()
+		if err != nil {
+			logger.Errorf("[oauth2][authorize] %s", err)
+			callback(errorURL(err.Error()))
+			return
+		}
+
+		WithParam("nonce", nonce)(opts)
+	}
+
+	loginURL, err := config.generateLoginURL(state, opts)
+	if err != nil {
+		logger.Errorf("[oauth2][authorize] %s", err)
+		callback(errorURL(err.Error()))
+		return
+	}
+
+	oa.saveState(state, &loginState{
+		config: config,
+		scopes: opts.Scopes,
+		nonce:  opts.Params.Get("nonce"),
+	})
+	callback(loginURL)
+}
+
+// Upgrade starts a re-authorization for the additional scopes (incremental authorization),
+// use token.HasScopes to decide whether it is required.
+func (oa *client) Upgrade(state string, scopes []string, callback func(loginUrl string), options ...Option) {
+	config := oa.config.Load()
+	upgrade := []Option{WithScopes(config.upgradeScopes(scopes)...)}
+	if config.IncludeGrantedScopes {
+		upgrade = append(upgrade, WithIncludeGrantedScopes())
+	}
+
+	oa.Authorize(state, callback, append(upgrade, options...)...)
 }
 
 // Callback is the second step of login,
 // means oauth server visit callback url with code.
 // And we will get access_token and refresh_token with the code.
 // Then we can use access_token to get user info.
-func (oa *client) Callback(code, state string, cb func(user *User, token *Token, err error)) {
+func (oa *client) Callback(code, state string, cb func(user *User, token *Token, err error), options ...Option) {
 	if len(code) == 0 || len(state) == 0 {
 		cb(nil, nil, errors.New("invalid oauth2 login callback, code or state are required"))
 		return
 	}
 
-	token, err := oa.GetToken(&oa.Config, code, state)
+	ls, ok := oa.loadState(state)
+	config := ls.config
+	if !ok && config.isOpenIDRequest(&Options{}) {
+		cb(nil, nil, fmt.Errorf("%w, state: %s", ErrLoginStateNotFound, state))
+		return
+	}
+
+	if len(ls.scopes) != 0 {
+		options = append([]Option{WithScopes(ls.scopes...)}, options...)
+	}
+
+	token, err := oa.GetToken(config, code, state, options...)
 	if err != nil {
 		cb(nil, nil, err)
 		return
 	}
+	token.nonce = ls.nonce
 
-	user, err := oa.GetUser(&oa.Config, token, code)
+	user, err := oa.GetUser(config, token, code)
 	if err != nil {
 		cb(nil, token, err)
 		return
@@ -66,17 +170,55 @@ func (oa *client) Callback(code, state string, cb func(user *User, token *Token,
 	cb(user, token, nil)
 }
 
-// Logout just to logout the user
-func (oa *client) Logout(state string, callback func(logoutUrl string)) {
-	callback(oa.generateLogoutURL(state))
+// Logout just to logout the user,
+// use WithIDTokenHint and WithPostLogoutRedirectURI for OpenID Connect RP-initiated logout.
+func (oa *client) Logout(state string, callback func(logoutUrl string), options ...Option) {
+	callback(oa.config.Load().generateLogoutURL(state, applyOptions(options)))
 }
 
 // Register just to register
 func (oa *client) Register(callback func(logoutUrl string)) {
-	callback(oa.generateRegisterURL())
+	callback(oa.config.Load().generateRegisterURL())
 }
 
 // RefreshToken refresh the token by refresh token.
-func (oa *client) RefreshToken(refreshToken string) (*Token, error) {
-	return RefreshToken(&oa.Config, refreshToken)
+func (oa *client) RefreshToken(refreshToken string, options ...Option) (*Token, error) {
+	return RefreshToken(oa.config.Load(), refreshToken, options...)
+}
+
+// saveState keeps the config and scopes of the authorize request for its callback.
+func (oa *client) saveState(state string, ls *loginState) {
+	if state == "" {
+		return
+	}
+
+	now := time.Now()
+	ls.expiresAt = now.Add(LoginStateTTL)
+	oa.states.Store(state, ls)
+
+	// prune the abandoned logins at most once per ttl
+	prunedAt := oa.prunedAt.Load()
+	if now.Sub(time.Unix(0, prunedAt)) < LoginStateTTL || !oa.prunedAt.CompareAndSwap(prunedAt, now.UnixNano()) {
+		return
+	}
+
+	oa.states.Range(func(key, value any) bool {
+		if now.After(value.(*loginState).expiresAt) {
+			oa.states.Delete(key)
+		}
+		return true
+	})
+}
+
+// loadState returns the state the login started with, or the current config and false if unknown or expired.
+func (oa *client) loadState(state string) (*loginState, bool) {
+	if value, ok := oa.states.LoadAndDelete(state); ok {
+		if ls := value.(*loginState); time.Now().Before(ls.expiresAt) {
+			return ls, true
+		}
+	}
+
+	return &loginState{
+		config: oa.config.Load(),
+	}, false
 }
@@ -14,6 +14,11 @@ type OktaConfig struct {
 	Scope        string `json:"scope"`
 	//
 	BaseURL string `json:"base_url"`
+	//
+	Audience string   `json:"audience"`
+	Resource []string `json:"resource"`
+	//
+	PostLogoutRedirectURI string `json:"post_logout_redirect_uri"`
 }
 
 func New(cfg *OktaConfig) (oauth2.Client, error) {
@@ -27,11 +32,16 @@ func New(cfg *OktaConfig) (oauth2.Client, error) {
 		AuthURL:      fmt.Sprintf("%s/oauth2/default/v1/authorize", cfg.BaseURL),
 		TokenURL:     fmt.Sprintf("%s/oauth2/default/v1/token", cfg.BaseURL),
 		UserInfoURL:  fmt.Sprintf("%s/oauth2/default/v1/userinfo", cfg.BaseURL),
-		LogoutURL:    fmt.Sprintf("%s/logout", cfg.BaseURL),
+		LogoutURL:    fmt.Sprintf("%s/oauth2/default/v1/logout", cfg.BaseURL),
 		Scope:        scope,
 		RedirectURI:  cfg.RedirectURI,
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
+		Audience:     cfg.Audience,
+		Resource:     cfg.Resource,
+		Issuer:       fmt.Sprintf("%s/oauth2/default", cfg.BaseURL),
+		//
+		PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
 		//
 		AccessTokenAttributeName:  "access_token",
 		RefreshTokenAttributeName: "refresh_token",
@@ -47,3 +57,25 @@ func New(cfg *OktaConfig) (oauth2.Client, error) {
 
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "okta",
+		DisplayName:    "Okta",
+		Icon:           "okta",
+		DefaultScopes:  []string{"profile", "email", "openid"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI", "BaseURL"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		return New(&OktaConfig{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+			BaseURL:      cfg.BaseURL,
+			Audience:     cfg.Audience,
+			Resource:     cfg.Resource,
+			//
+			PostLogoutRedirectURI: cfg.PostLogoutRedirectURI,
+		})
+	})
+}
@@ -0,0 +1,212 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Option is the option of authorize, token and refresh requests.
+type Option func(opts *Options)
+
+// Options is the per-call options of authorize, token and refresh requests.
+type Options struct {
+	// Resource is the resource indicators (RFC 8707), overrides Config.Resource.
+	Resource []string
+	// Audience is the audience parameter (Auth0), overrides Config.Audience.
+	Audience string
+	// AuthorizationDetails is the authorization_details (RFC 9396) of authorize request,
+	// overrides Config.AuthorizationDetails.
+	AuthorizationDetails []AuthorizationDetail
+	// Scopes overrides the scope of the authorize request.
+	Scopes []string
+	// Params are the extra parameters of authorize request, such as prompt and login_hint.
+	Params url.Values
+
+	// err is the error of the options, such as the claims cannot be encoded,
+	// which fails the authorize request.
+	err error
+}
+
+// Prompt values of OpenID Connect authorize request.
+const (
+	PromptNone          = "none"
+	PromptLogin         = "login"
+	PromptConsent       = "consent"
+	PromptSelectAccount = "select_account"
+)
+
+// WithResource sets the resource indicators (RFC 8707) of the request.
+func WithResource(resource ...string) Option {
+	return func(opts *Options) {
+		opts.Resource = append(opts.Resource, resource...)
+	}
+}
+
+// WithAudience sets the audience of the request.
+func WithAudience(audience string) Option {
+	return func(opts *Options) {
+		opts.Audience = audience
+	}
+}
+
+// WithAuthorizationDetails sets the authorization_details (RFC 9396) of the authorize request.
+func WithAuthorizationDetails(details ...AuthorizationDetail) Option {
+	return func(opts *Options) {
+		opts.AuthorizationDetails = append(opts.AuthorizationDetails, details...)
+	}
+}
+
+// WithScopes sets the scopes of the authorize request, overrides Config.Scope.
+func WithScopes(scopes ...string) Option {
+	return func(opts *Options) {
+		opts.Scopes = append(opts.Scopes, scopes...)
+	}
+}
+
+// WithIncludeGrantedScopes asks the provider to include the previously granted scopes,
+// which is supported by Google.
+func WithIncludeGrantedScopes() Option {
+	return WithParam("include_granted_scopes", "true")
+}
+
+// WithParam sets an extra parameter of the authorize request.
+func WithParam(key, value string) Option {
+	return func(opts *Options) {
+		if opts.Params == nil {
+			opts.Params = url.Values{}
+		}
+
+		opts.Params.Set(key, value)
+	}
+}
+
+// WithPrompt sets the prompt parameter, such as PromptSelectAccount.
+func WithPrompt(prompt ...string) Option {
+	return WithParam("prompt", strings.Join(prompt, " "))
+}
+
+// WithLoginHint sets the login_hint parameter to pre-fill the login identifier.
+func WithLoginHint(hint string) Option {
+	return WithParam("login_hint", hint)
+}
+
+// WithMaxAge sets the max_age parameter, forces re-authentication when the last one is older.
+func WithMaxAge(maxAge time.Duration) Option {
+	return WithParam("max_age", strconv.FormatInt(int64(maxAge/time.Second), 10))
+}
+
+// WithACRValues sets the acr_values parameter, such as the requested MFA level.
+func WithACRValues(values ...string) Option {
+	return WithParam("acr_values", strings.Join(values, " "))
+}
+
+// WithClaims sets the claims request parameter,
+// claims is a JSON string, json.RawMessage or a value which will be encoded as JSON,
+// the authorize request fails if it is not valid JSON.
+func WithClaims(claims any) Option {
+	if value, ok := claims.(string); ok {
+		claims = json.RawMessage(value)
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return func(opts *Options) {
+			opts.err = fmt.Errorf("oauth2: failed to encode claims: %s", err)
+		}
+	}
+
+	return WithParam("claims", string(data))
+}
+
+// WithUILocales sets the ui_locales parameter, such as zh-CN en.
+func WithUILocales(locales ...string) Option {
+	return WithParam("ui_locales", strings.Join(locales, " "))
+}
+
+func applyOptions(options []Option) *Options {
+	opts := &Options{}
+	for _, option := range options {
+		if option != nil {
+			option(opts)
+		}
+	}
+
+	return opts
+}
+
+// resourceParams returns the resource and audience parameters,
+// per-call options take precedence over the config.
+func (oac *Config) resourceParams(opts *Options) url.Values {
+	params := url.Values{}
+
+	resource := oac.Resource
+	if len(opts.Resource) != 0 {
+		resource = opts.Resource
+	}
+	for _, r := range resource {
+		if r != "" {
+			params.Add("resource", r)
+		}
+	}
+
+	audience := oac.Audience
+	if opts.Audience != "" {
+		audience = opts.Audience
+	}
+	if audience != "" {
+		params.Set("audience", audience)
+	}
+
+	return params
+}
+
+// authorizeParams returns the extra parameters of the authorize request,
+// invalid authorization_details are an error instead of being dropped.
+func (oac *Config) authorizeParams(opts *Options) (url.Values, error) {
+	if opts.err != nil {
+		return nil, opts.err
+	}
+
+	params := oac.resourceParams(opts)
+
+	details := oac.AuthorizationDetails
+	if len(opts.AuthorizationDetails) != 0 {
+		details = opts.AuthorizationDetails
+	}
+	if len(details) != 0 {
+		encoded, err := encodeAuthorizationDetails(details)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Set("authorization_details", encoded)
+	}
+
+	for key, value := range oac.AuthParams {
+		params.Set(key, value)
+	}
+
+	for key, values := range opts.Params {
+		params[key] = values
+	}
+
+	return params, nil
+}
+
+// appendQuery appends the params to the query of rawURL.
+func appendQuery(rawURL string, params url.Values) string {
+	if len(params) == 0 {
+		return rawURL
+	}
+
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+
+	return rawURL + separator + params.Encode()
+}
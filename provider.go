@@ -0,0 +1,160 @@
+package oauth2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ProviderInfo is the metadata of a provider.
+type ProviderInfo struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	// Icon is the icon name or url.
+	Icon          string   `json:"icon"`
+	DefaultScopes []string `json:"default_scopes"`
+	// RequiredFields are the required Config fields, such as ClientID and BaseURL.
+	RequiredFields []string `json:"required_fields"`
+}
+
+// ProviderFactory creates the client of the provider with the config.
+type ProviderFactory func(cfg *Config) (Client, error)
+
+type provider struct {
+	info    *ProviderInfo
+	factory ProviderFactory
+}
+
+var providers = struct {
+	sync.RWMutex
+	items map[string]*provider
+}{
+	items: map[string]*provider{},
+}
+
+// RegisterProvider registers the provider factory, the id must be unique.
+func RegisterProvider(info *ProviderInfo, factory ProviderFactory) error {
+	if info == nil || info.ID == "" {
+		return fmt.Errorf("oauth2: provider id is empty")
+	}
+
+	if factory == nil {
+		return fmt.Errorf("oauth2: provider(%s) factory is nil", info.ID)
+	}
+
+	for _, field := range info.RequiredFields {
+		if _, ok := reflect.TypeOf(Config{}).FieldByName(field); !ok {
+			return fmt.Errorf("oauth2: provider(%s) required field %s is not a Config field", info.ID, field)
+		}
+	}
+
+	providers.Lock()
+	defer providers.Unlock()
+
+	if _, ok := providers.items[info.ID]; ok {
+		return fmt.Errorf("oauth2: provider(%s) already registered", info.ID)
+	}
+
+	if info.DisplayName == "" {
+		info.DisplayName = info.ID
+	}
+
+	providers.items[info.ID] = &provider{
+		info:    info,
+		factory: factory,
+	}
+	return nil
+}
+
+// MustRegisterProvider registers the provider factory and panics on error,
+// which is used in the init of provider packages.
+func MustRegisterProvider(info *ProviderInfo, factory ProviderFactory) {
+	if err := RegisterProvider(info, factory); err != nil {
+		panic(err)
+	}
+}
+
+// UnregisterProvider removes the provider factory.
+func UnregisterProvider(id string) {
+	providers.Lock()
+	defer providers.Unlock()
+
+	delete(providers.items, id)
+}
+
+// LookupProvider gets the provider metadata and factory by id.
+func LookupProvider(id string) (*ProviderInfo, ProviderFactory, error) {
+	providers.RLock()
+	defer providers.RUnlock()
+
+	p, ok := providers.items[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("oauth2: provider(%s) not supported", id)
+	}
+
+	info := *p.info
+	return &info, p.factory, nil
+}
+
+// Providers returns the metadata of all the registered providers, sorted by id.
+func Providers() []*ProviderInfo {
+	providers.RLock()
+	defer providers.RUnlock()
+
+	infos := make([]*ProviderInfo, 0, len(providers.items))
+	for _, p := range providers.items {
+		info := *p.info
+		infos = append(infos, &info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// CreateProvider creates the client of the registered provider,
+// the required fields of the provider are checked first.
+func CreateProvider(id string, cfg *Config) (Client, error) {
+	info, factory, err := LookupProvider(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	errs := Errors{}
+	value := reflect.ValueOf(cfg).Elem()
+	for _, field := range info.RequiredFields {
+		if value.FieldByName(field).IsZero() {
+			errs.Add(fmt.Errorf("oauth2: provider(%s) config %s is required", id, field))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	client, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// the claim and role mappings are not known by the provider factories
+	if len(cfg.ClaimMappings) != 0 || cfg.RoleMapping != nil {
+		config := client.Config()
+		if len(cfg.ClaimMappings) != 0 {
+			config.ClaimMappings = mergeClaimMappings(config.ClaimMappings, cfg.ClaimMappings)
+		}
+		if cfg.RoleMapping != nil {
+			config.RoleMapping = cfg.RoleMapping
+		}
+		if err := client.Update(*config); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,29 @@
+package oauth2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeProviderOptions decodes Config.ProviderOptions into the provider specific config by its json field names,
+// such as fetch_teams of github.GitHubConfig, which is used by the provider factories.
+// Unknown options are an error, so a typo never drops a restriction silently.
+func DecodeProviderOptions(cfg *Config, v any) error {
+	if len(cfg.ProviderOptions) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(cfg.ProviderOptions)
+	if err != nil {
+		return fmt.Errorf("oauth2: invalid provider options: %s", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("oauth2: invalid provider options: %s", err)
+	}
+
+	return nil
+}
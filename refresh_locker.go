@@ -0,0 +1,73 @@
+package oauth2
+
+import (
+	"context"
+	"sync"
+)
+
+// RefreshLocker coordinates the refreshes of the same token, so a rotated refresh token is not reused,
+// implement it with a distributed lock (such as redis) when the tokens are shared by many instances.
+type RefreshLocker interface {
+	// Lock blocks until the key is locked or the context is done, unlock releases the lock.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// defaultRefreshLocker is the RefreshLocker of StoredTokenSource if not specified.
+var defaultRefreshLocker = NewRefreshLocker()
+
+// memoryRefreshLocker is the in-process RefreshLocker.
+type memoryRefreshLocker struct {
+	mu    sync.Mutex
+	locks map[string]*refreshLock
+}
+
+type refreshLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewRefreshLocker creates an in-process RefreshLocker.
+func NewRefreshLocker() RefreshLocker {
+	return &memoryRefreshLocker{
+		locks: map[string]*refreshLock{},
+	}
+}
+
+// Lock locks the key.
+func (l *memoryRefreshLocker) Lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &refreshLock{
+			ch: make(chan struct{}, 1),
+		}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	select {
+	case lock.ch <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-lock.ch
+				l.release(key, lock)
+			})
+		}, nil
+	case <-ctx.Done():
+		l.release(key, lock)
+		return nil, ctx.Err()
+	}
+}
+
+// release removes the lock of the key when nobody holds or waits for it.
+func (l *memoryRefreshLocker) release(key string, lock *refreshLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, key)
+	}
+}
@@ -0,0 +1,271 @@
+package oauth2
+
+// reference:
+//	https://www.rfc-editor.org/rfc/rfc7591
+//	https://www.rfc-editor.org/rfc/rfc7592
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-zoox/fetch"
+	"github.com/go-zoox/logger"
+	"github.com/go-zoox/oauth2/jwt"
+)
+
+// ClientMetadata is the client metadata of dynamic client registration (RFC 7591).
+type ClientMetadata struct {
+	RedirectURIs            []string           `json:"redirect_uris,omitempty"`
+	GrantTypes              []string           `json:"grant_types,omitempty"`
+	ResponseTypes           []string           `json:"response_types,omitempty"`
+	TokenEndpointAuthMethod string             `json:"token_endpoint_auth_method,omitempty"`
+	ClientName              string             `json:"client_name,omitempty"`
+	ClientURI               string             `json:"client_uri,omitempty"`
+	LogoURI                 string             `json:"logo_uri,omitempty"`
+	Scope                   string             `json:"scope,omitempty"`
+	Contacts                []string           `json:"contacts,omitempty"`
+	JWKSURI                 string             `json:"jwks_uri,omitempty"`
+	JWKS                    *jwt.JSONWebKeySet `json:"jwks,omitempty"`
+	SoftwareID              string             `json:"software_id,omitempty"`
+	SoftwareVersion         string             `json:"software_version,omitempty"`
+	// OpenID Connect
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+	FrontChannelLogoutURI  string   `json:"frontchannel_logout_uri,omitempty"`
+	BackChannelLogoutURI   string   `json:"backchannel_logout_uri,omitempty"`
+}
+
+// ClientRegistration is the client information response of dynamic client registration.
+type ClientRegistration struct {
+	ClientMetadata
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at,omitempty"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at,omitempty"`
+	// RFC 7592, used to read, update and delete the registration
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri,omitempty"`
+}
+
+// RegisterClient registers a client at the registration endpoint,
+// initialAccessToken is optional, which is required by some providers, such as Keycloak.
+func RegisterClient(endpoint string, initialAccessToken string, metadata *ClientMetadata) (*ClientRegistration, error) {
+	if endpoint == "" {
+		return nil, errors.New("oauth2: registration endpoint is empty")
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	if initialAccessToken != "" {
+		headers["Authorization"] = "Bearer " + initialAccessToken
+	}
+
+	response, err := fetch.Post(endpoint, &fetch.Config{
+		Headers: headers,
+		Body:    metadata,
+	})
+	if err != nil {
+		return nil, errors.New("register client error: " + err.Error())
+	}
+
+	logger.Debugf("[oauth2][RegisterClient]: %s", response.String())
+
+	return parseClientRegistration(response, http.StatusCreated)
+}
+
+// RegisterClientByIssuer registers a client at the registration_endpoint from the discovery of issuer,
+// and returns a ready-to-use Config.
+func RegisterClientByIssuer(issuer string, initialAccessToken string, metadata *ClientMetadata) (*Config, *ClientRegistration, error) {
+	provider, err := Discover(issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if provider.RegistrationEndpoint == "" {
+		return nil, nil, fmt.Errorf("oauth2: provider(%s) does not support dynamic client registration", issuer)
+	}
+
+	registration, err := RegisterClient(provider.RegistrationEndpoint, initialAccessToken, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := registration.Config(provider)
+	return &config, registration, nil
+}
+
+// Config creates the Config of the registered client with the provider metadata.
+func (r *ClientRegistration) Config(provider *ProviderMetadata) Config {
+	config := Config{
+		Name:         r.ClientName,
+		AuthURL:      provider.AuthorizationEndpoint,
+		TokenURL:     provider.TokenEndpoint,
+		UserInfoURL:  provider.UserInfoEndpoint,
+		LogoutURL:    provider.EndSessionEndpoint,
+		Issuer:       provider.Issuer,
+		Scope:        r.Scope,
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+		//
+		IDAttributeName:       "sub",
+		UsernameAttributeName: "preferred_username",
+		NicknameAttributeName: "name",
+		AvatarAttributeName:   "picture",
+	}
+
+	if len(r.RedirectURIs) != 0 {
+		config.RedirectURI = r.RedirectURIs[0]
+	}
+
+	if len(r.PostLogoutRedirectURIs) != 0 {
+		config.PostLogoutRedirectURI = r.PostLogoutRedirectURIs[0]
+	}
+
+	config.AuthStyle = r.authStyle()
+
+	return config
+}
+
+// authStyle returns the AuthStyle of the token_endpoint_auth_method,
+// which is client_secret_basic if omitted (RFC 7591 section 2).
+func (r *ClientRegistration) authStyle() string {
+	switch r.TokenEndpointAuthMethod {
+	case "none":
+		return AuthStyleNone
+	case "client_secret_basic":
+		return AuthStyleInHeader
+	case "client_secret_post":
+		return AuthStyleInParams
+	case "":
+		if r.ClientSecret == "" {
+			return AuthStyleNone
+		}
+
+		return AuthStyleInHeader
+	default:
+		return ""
+	}
+}
+
+// ReadClient reads the current registration with the registration access token (RFC 7592).
+func ReadClient(registration *ClientRegistration) (*ClientRegistration, error) {
+	if err := registration.validateManagement(); err != nil {
+		return nil, err
+	}
+
+	response, err := fetch.Get(registration.RegistrationClientURI, &fetch.Config{
+		Headers: registration.managementHeaders(),
+	})
+	if err != nil {
+		return nil, errors.New("read client error: " + err.Error())
+	}
+
+	return registration.keepManagement(parseClientRegistration(response, http.StatusOK))
+}
+
+// UpdateClient replaces the client metadata with the registration access token (RFC 7592).
+func UpdateClient(registration *ClientRegistration, metadata *ClientMetadata) (*ClientRegistration, error) {
+	if err := registration.validateManagement(); err != nil {
+		return nil, err
+	}
+
+	// the request must contain client_id and all the metadata, except the management fields
+	body := &ClientRegistration{
+		ClientMetadata: *metadata,
+		ClientID:       registration.ClientID,
+		ClientSecret:   registration.ClientSecret,
+	}
+
+	headers := registration.managementHeaders()
+	headers["Content-Type"] = "application/json"
+
+	response, err := fetch.Put(registration.RegistrationClientURI, &fetch.Config{
+		Headers: headers,
+		Body:    body,
+	})
+	if err != nil {
+		return nil, errors.New("update client error: " + err.Error())
+	}
+
+	return registration.keepManagement(parseClientRegistration(response, http.StatusOK))
+}
+
+// DeleteClient deletes the registration with the registration access token (RFC 7592).
+func DeleteClient(registration *ClientRegistration) error {
+	if err := registration.validateManagement(); err != nil {
+		return err
+	}
+
+	response, err := fetch.Delete(registration.RegistrationClientURI, &fetch.Config{
+		Headers: registration.managementHeaders(),
+	})
+	if err != nil {
+		return errors.New("delete client error: " + err.Error())
+	}
+
+	if response.Status != http.StatusNoContent && !response.Ok() {
+		return fmt.Errorf("delete client error: %s", registrationError(response))
+	}
+
+	return nil
+}
+
+func (r *ClientRegistration) validateManagement() error {
+	if r.RegistrationClientURI == "" || r.RegistrationAccessToken == "" {
+		return errors.New("oauth2: registration_client_uri and registration_access_token are required to manage the client")
+	}
+
+	return nil
+}
+
+func (r *ClientRegistration) managementHeaders() map[string]string {
+	return map[string]string{
+		"Accept":        "application/json",
+		"Authorization": "Bearer " + r.RegistrationAccessToken,
+	}
+}
+
+// keepManagement keeps the registration access token and uri when the provider does not rotate them.
+func (r *ClientRegistration) keepManagement(registration *ClientRegistration, err error) (*ClientRegistration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if registration.RegistrationAccessToken == "" {
+		registration.RegistrationAccessToken = r.RegistrationAccessToken
+	}
+
+	if registration.RegistrationClientURI == "" {
+		registration.RegistrationClientURI = r.RegistrationClientURI
+	}
+
+	return registration, nil
+}
+
+func parseClientRegistration(response *fetch.Response, status int) (*ClientRegistration, error) {
+	if response.Status != status && !response.Ok() {
+		return nil, fmt.Errorf("client registration error: %s", registrationError(response))
+	}
+
+	registration := &ClientRegistration{}
+	if err := json.Unmarshal(response.Body, registration); err != nil {
+		return nil, fmt.Errorf("client registration error: invalid response: %s", err)
+	}
+
+	if registration.ClientID == "" {
+		return nil, fmt.Errorf("client registration error: client_id is empty, response: %s", response.String())
+	}
+
+	return registration, nil
+}
+
+func registrationError(response *fetch.Response) string {
+	if code := response.Get("error").String(); code != "" {
+		return fmt.Sprintf("%s (%s)", code, response.Get("error_description").String())
+	}
+
+	return response.Error().Error()
+}
@@ -0,0 +1,256 @@
+package oauth2
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultTenant is the tenant used by the global Register and Get.
+const DefaultTenant = ""
+
+// DefaultRegistry is the global registry used by Register and Get.
+var DefaultRegistry = NewRegistry()
+
+// RegistryEntry is a registered provider of a tenant.
+type RegistryEntry struct {
+	Tenant   string
+	Provider string
+	Config   *Config
+	// Client is the client created by the registry or given by RegisterClient,
+	// it is nil until the first Client lookup if the registry creates it.
+	Client Client
+}
+
+type registryKey struct {
+	tenant   string
+	provider string
+}
+
+// Registry holds the named provider configs and clients by tenant,
+// it is safe for concurrent use and the entries can be replaced at runtime.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[registryKey]*RegistryEntry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: map[registryKey]*RegistryEntry{},
+	}
+}
+
+// Register registers the provider config of the tenant, the provider must not be registered.
+// The client is created with oauth2.New on the first Client lookup.
+func (r *Registry) Register(tenant, provider string, cfg *Config) error {
+	return r.set(tenant, provider, cfg, nil, false)
+}
+
+// RegisterClient registers a ready-made client with its config.
+func (r *Registry) RegisterClient(tenant, provider string, cfg *Config, client Client) error {
+	if client == nil {
+		return fmt.Errorf("oauth2: provider(%s) client is nil", provider)
+	}
+
+	return r.set(tenant, provider, cfg, client, false)
+}
+
+// Replace registers or replaces the provider config of the tenant,
+// the live client is updated in place, so the clients already returned use the new config
+// while their in-flight logins complete with the previous one.
+func (r *Registry) Replace(tenant, provider string, cfg *Config) error {
+	return r.set(tenant, provider, cfg, nil, true)
+}
+
+// ReplaceClient registers or replaces the provider client of the tenant.
+func (r *Registry) ReplaceClient(tenant, provider string, cfg *Config, client Client) error {
+	if client == nil {
+		return fmt.Errorf("oauth2: provider(%s) client is nil", provider)
+	}
+
+	return r.set(tenant, provider, cfg, client, true)
+}
+
+func (r *Registry) set(tenant, provider string, cfg *Config, client Client, replace bool) error {
+	if provider == "" {
+		return fmt.Errorf("oauth2: provider is empty")
+	}
+
+	if cfg == nil {
+		return fmt.Errorf("oauth2: provider(%s) config is nil", provider)
+	}
+
+	if cfg.Name == "" {
+		cfg.Name = provider
+	}
+
+	key := registryKey{tenant: tenant, provider: provider}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.entries[key]
+	if ok && !replace {
+		return fmt.Errorf("oauth2: provider(%s) already registered", r.name(tenant, provider))
+	}
+
+	if ok && client == nil && current.Client != nil {
+		if err := current.Client.Update(*cfg); err != nil {
+			return err
+		}
+
+		client = current.Client
+	}
+
+	r.entries[key] = &RegistryEntry{
+		Tenant:   tenant,
+		Provider: provider,
+		Config:   cfg,
+		Client:   client,
+	}
+	return nil
+}
+
+// Remove removes the provider of the tenant, it returns false if not registered.
+func (r *Registry) Remove(tenant, provider string) bool {
+	key := registryKey{tenant: tenant, provider: provider}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[key]; !ok {
+		return false
+	}
+
+	delete(r.entries, key)
+	return true
+}
+
+// Has returns whether the provider of the tenant is registered.
+func (r *Registry) Has(tenant, provider string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.entries[registryKey{tenant: tenant, provider: provider}]
+	return ok
+}
+
+// Get gets the provider config of the tenant.
+func (r *Registry) Get(tenant, provider string) (*Config, error) {
+	entry, err := r.entry(tenant, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.Config, nil
+}
+
+// Client gets the provider client of the tenant,
+// which is created from the registered config on the first lookup.
+func (r *Registry) Client(tenant, provider string) (Client, error) {
+	entry, err := r.entry(tenant, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Client != nil {
+		return entry.Client, nil
+	}
+
+	client, err := New(*entry.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	key := registryKey{tenant: tenant, provider: provider}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the entry may be replaced while creating the client
+	if current, ok := r.entries[key]; ok && current == entry {
+		if entry.Client != nil {
+			return entry.Client, nil
+		}
+
+		r.entries[key] = &RegistryEntry{
+			Tenant:   entry.Tenant,
+			Provider: entry.Provider,
+			Config:   entry.Config,
+			Client:   client,
+		}
+	}
+
+	return client, nil
+}
+
+// List lists the entries of the tenant sorted by provider,
+// or the entries of all tenants sorted by tenant and provider if no tenant is given.
+func (r *Registry) List(tenant ...string) []RegistryEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []RegistryEntry{}
+	for key, entry := range r.entries {
+		if len(tenant) > 0 && key.tenant != tenant[0] {
+			continue
+		}
+
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Tenant != entries[j].Tenant {
+			return entries[i].Tenant < entries[j].Tenant
+		}
+
+		return entries[i].Provider < entries[j].Provider
+	})
+
+	return entries
+}
+
+// Tenants lists the tenants in order.
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{}
+	tenants := []string{}
+	for key := range r.entries {
+		if seen[key.tenant] {
+			continue
+		}
+
+		seen[key.tenant] = true
+		tenants = append(tenants, key.tenant)
+	}
+
+	sort.Strings(tenants)
+	return tenants
+}
+
+func (r *Registry) entry(tenant, provider string) (*RegistryEntry, error) {
+	if provider == "" {
+		return nil, fmt.Errorf("oauth2: provider is empty")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[registryKey{tenant: tenant, provider: provider}]
+	if !ok {
+		return nil, fmt.Errorf("oauth2: provider(%s) not registered", r.name(tenant, provider))
+	}
+
+	return entry, nil
+}
+
+func (r *Registry) name(tenant, provider string) string {
+	if tenant == DefaultTenant {
+		return provider
+	}
+
+	return tenant + "/" + provider
+}
@@ -0,0 +1,57 @@
+package oauth2
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-zoox/fetch"
+)
+
+// postForm posts the form to url.
+//
+// fetch only accepts map[string]string as form body,
+// which cannot carry repeated parameters, such as resource (RFC 8707).
+func postForm(client *http.Client, rawURL string, headers map[string]string, form url.Values) (*fetch.Response, error) {
+	request, err := http.NewRequest(http.MethodPost, rawURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to create request: %s", err)
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read response: %s", err)
+	}
+
+	return &fetch.Response{
+		Status:  response.StatusCode,
+		Headers: response.Header,
+		Body:    body,
+	}, nil
+}
+
+// httpClient returns the client of the token requests,
+// default: a client with the timeout of fetch, as the other requests.
+func (oac *Config) httpClient() *http.Client {
+	if oac.HTTPClient != nil {
+		return oac.HTTPClient
+	}
+
+	return &http.Client{
+		Timeout: fetch.Timeout,
+	}
+}
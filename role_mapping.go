@@ -0,0 +1,170 @@
+package oauth2
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/tidwall/gjson"
+)
+
+// Role rule effects.
+const (
+	// RoleEffectAllow grants the role if the rule matches.
+	RoleEffectAllow = "allow"
+	// RoleEffectDeny revokes the role if the rule matches, which takes precedence over allow,
+	// the role * revokes all the roles.
+	RoleEffectDeny = "deny"
+)
+
+// ErrUserAccessDenied is returned by GetUser when the user is not allowed to login,
+// such as no role is granted while the role mapping requires one, use errors.Is to check.
+var ErrUserAccessDenied = errors.New("oauth2: user access denied")
+
+// RoleMapping maps the provider groups and claims to application roles.
+type RoleMapping struct {
+	Rules []RoleRule `json:"rules" yaml:"rules"`
+	// DefaultRoles are granted to all the users, unless denied.
+	DefaultRoles []string `json:"default_roles,omitempty" yaml:"default_roles,omitempty"`
+	// RequireRole denies the login of the users without any role.
+	RequireRole bool `json:"require_role,omitempty" yaml:"require_role,omitempty"`
+}
+
+// RoleRule matches a claim of the user, such as Okta groups, Entra roles or GitHub team slugs.
+type RoleRule struct {
+	Role string `json:"role" yaml:"role"`
+	// Claim is groups, permissions or a gjson path of the user info response, default: groups.
+	Claim string `json:"claim,omitempty" yaml:"claim,omitempty"`
+	// Values are the patterns of path.Match, such as admins or org/*, the rule matches if any value matches.
+	Values []string `json:"values" yaml:"values"`
+	// Effect is allow (default) or deny.
+	Effect string `json:"effect,omitempty" yaml:"effect,omitempty"`
+}
+
+// Roles evaluates the role mapping of the user, the roles are sorted.
+func (m *RoleMapping) Roles(user *User) []string {
+	granted := map[string]bool{}
+	for _, role := range m.DefaultRoles {
+		granted[role] = true
+	}
+
+	denied := map[string]bool{}
+	for _, rule := range m.Rules {
+		if !rule.matches(user) {
+			continue
+		}
+
+		if rule.Effect == RoleEffectDeny {
+			denied[rule.Role] = true
+		} else {
+			granted[rule.Role] = true
+		}
+	}
+
+	roles := []string{}
+	if denied["*"] {
+		return roles
+	}
+
+	for role := range granted {
+		if !denied[role] {
+			roles = append(roles, role)
+		}
+	}
+
+	sort.Strings(roles)
+	return roles
+}
+
+// Validate validates the rules and reports all the problems together.
+func (m *RoleMapping) Validate() error {
+	errs := Errors{}
+	for i, rule := range m.Rules {
+		switch rule.Effect {
+		case "", RoleEffectAllow:
+			if rule.Role == "" || rule.Role == "*" {
+				errs.Add(fmt.Errorf("oauth2: role rule %d must grant a role", i))
+			}
+		case RoleEffectDeny:
+			if rule.Role == "" {
+				errs.Add(fmt.Errorf("oauth2: role rule %d role is required", i))
+			}
+		default:
+			errs.Add(fmt.Errorf("oauth2: role rule %d effect(%s) is unknown", i, rule.Effect))
+		}
+
+		if len(rule.Values) == 0 {
+			errs.Add(fmt.Errorf("oauth2: role rule %d values are required", i))
+		}
+
+		for _, value := range rule.Values {
+			if _, err := path.Match(value, ""); err != nil {
+				errs.Add(fmt.Errorf("oauth2: role rule %d value(%s) is not a valid pattern", i, value))
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// matches reports whether any claim value of the user matches the rule.
+func (r *RoleRule) matches(user *User) bool {
+	for _, claim := range r.claimValues(user) {
+		for _, pattern := range r.Values {
+			if matched, _ := path.Match(pattern, claim); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *RoleRule) claimValues(user *User) []string {
+	switch r.Claim {
+	case "", "groups":
+		return user.Groups
+	case "permissions":
+		return user.Permissions
+	}
+
+	values := []string{}
+	var result gjson.Result
+	if user.raw != nil {
+		result = user.raw.Get(r.Claim)
+	}
+
+	if result.IsArray() {
+		result.ForEach(func(key, value gjson.Result) bool {
+			values = append(values, value.String())
+			return true
+		})
+	} else if result.Exists() {
+		values = append(values, result.String())
+	}
+
+	return values
+}
+
+// HasRole reports whether the user has the role.
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAnyRole reports whether the user has any of the roles.
+func (u *User) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.HasRole(role) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package oauth2
+
+import (
+	"strings"
+
+	"github.com/go-zoox/fetch"
+)
+
+// parseScopes splits the scope string, both space and comma separated scopes are supported,
+// such as "openid profile" and GitHub's "repo,user:email".
+func parseScopes(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+// mergeScopes merges the scopes and removes the duplicated.
+func mergeScopes(scopes ...[]string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, group := range scopes {
+		for _, scope := range group {
+			if scope == "" || seen[scope] {
+				continue
+			}
+
+			seen[scope] = true
+			merged = append(merged, scope)
+		}
+	}
+
+	return merged
+}
+
+// grantedScopes returns the granted scopes of the token response,
+// the requested scopes (options, then config) if the scope is omitted, which means they are granted as is (RFC 6749 section 5.1).
+func (oac *Config) grantedScopes(response *fetch.Response, opts *Options) []string {
+	if scope := response.Get(oac.GrantedScopeAttributeName).String(); scope != "" {
+		return parseScopes(scope)
+	}
+
+	if len(opts.Scopes) != 0 {
+		return mergeScopes(opts.Scopes)
+	}
+
+	return parseScopes(oac.Scope)
+}
+
+// scopeSeparator returns the separator of scopes in authorize request.
+func (oac *Config) scopeSeparator() string {
+	if oac.ScopeSeparator == "" {
+		return " "
+	}
+
+	return oac.ScopeSeparator
+}
+
+// upgradeScopes returns the scopes of the re-authorization for additional scopes.
+func (oac *Config) upgradeScopes(scopes []string) []string {
+	if oac.IncludeGrantedScopes {
+		// the provider merges the previously granted scopes itself
+		return mergeScopes(scopes)
+	}
+
+	return mergeScopes(parseScopes(oac.Scope), scopes)
+}
+
+// HasScopes reports whether all the scopes are granted to the token.
+func (u *Token) HasScopes(scopes ...string) bool {
+	return len(u.MissingScopes(scopes...)) == 0
+}
+
+// MissingScopes returns the scopes which are not granted to the token.
+func (u *Token) MissingScopes(scopes ...string) []string {
+	granted := map[string]bool{}
+	for _, scope := range u.Scopes {
+		granted[scope] = true
+	}
+
+	missing := []string{}
+	for _, scope := range scopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+
+	return missing
+}
@@ -29,9 +29,11 @@ func New(cfg *SlackConfig) (oauth2.Client, error) {
 		ClientSecret: cfg.ClientSecret,
 		//
 		ScopeAttributeName: "user_scope",
+		ScopeSeparator:     ",",
 		//
-		AccessTokenAttributeName: "authed_user.access_token",
-		TokenTypeAttributeName:   "authed_user.token_type",
+		AccessTokenAttributeName:  "authed_user.access_token",
+		TokenTypeAttributeName:    "authed_user.token_type",
+		GrantedScopeAttributeName: "authed_user.scope",
 		//
 		EmailAttributeName:    "user.email",
 		IDAttributeName:       "user.id",
@@ -41,3 +43,20 @@ func New(cfg *SlackConfig) (oauth2.Client, error) {
 
 	return oauth2.New(config)
 }
+
+func init() {
+	oauth2.MustRegisterProvider(&oauth2.ProviderInfo{
+		ID:             "slack",
+		DisplayName:    "Slack",
+		Icon:           "slack",
+		DefaultScopes:  []string{"identity.basic", "identity.email", "identity.avatar"},
+		RequiredFields: []string{"ClientID", "ClientSecret", "RedirectURI"},
+	}, func(cfg *oauth2.Config) (oauth2.Client, error) {
+		return New(&SlackConfig{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURI:  cfg.RedirectURI,
+			Scope:        cfg.Scope,
+		})
+	})
+}
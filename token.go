@@ -1,7 +1,10 @@
 package oauth2
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/go-zoox/fetch"
 	"github.com/go-zoox/logger"
@@ -13,8 +16,62 @@ type Token struct {
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"`
 	TokenType    string `json:"token_type"`
+	IDToken      string `json:"id_token,omitempty"`
+	// Expiry is the time when the access token expires, zero means never.
+	Expiry time.Time `json:"expiry"`
+	// Scopes are the granted scopes, parsed from the scope of token response, or the requested scopes if omitted.
+	Scopes []string `json:"scopes,omitempty"`
+	// AuthorizationDetails is the granted authorization_details (RFC 9396).
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
 	//
 	raw *fetch.Response
+	// nonce is the nonce of the login, empty for refreshed or restored tokens.
+	nonce string
+}
+
+// Nonce returns the nonce of the authorize request of the token,
+// which is the expected nonce of its id_token, see IDTokenVerifier.Nonce.
+func (u *Token) Nonce() string {
+	return u.nonce
+}
+
+// expiryDelta is how earlier a token is considered expired than its actual expiration time.
+const expiryDelta = 10 * time.Second
+
+// Valid reports whether the token has an access token and is not expired.
+func (u *Token) Valid() bool {
+	if u == nil || u.AccessToken == "" {
+		return false
+	}
+
+	if u.Expiry.IsZero() {
+		return true
+	}
+
+	return time.Now().Add(expiryDelta).Before(u.Expiry)
+}
+
+// parseAuthorizationDetails parses the granted authorization_details from the token response.
+func (u *Token) parseAuthorizationDetails(response *fetch.Response) error {
+	details := response.Get("authorization_details")
+	if !details.IsArray() {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(details.Raw), &u.AuthorizationDetails); err != nil {
+		return fmt.Errorf("oauth2: invalid authorization_details in token response: %s", err)
+	}
+
+	return nil
+}
+
+// expiryFromExpiresIn computes the expiry time from the expires_in seconds.
+func expiryFromExpiresIn(expiresIn int64) time.Time {
+	if expiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(time.Duration(expiresIn) * time.Second)
 }
 
 // Raw gets raw data with *fetch.Response.
@@ -22,13 +79,41 @@ func (u *Token) Raw() *fetch.Response {
 	return u.raw
 }
 
+type tokenJSON Token
+
+// MarshalJSON marshals the token with the raw token response.
+func (u Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		tokenJSON
+		Raw json.RawMessage `json:"raw,omitempty"`
+	}{
+		tokenJSON: tokenJSON(u),
+		Raw:       marshalRaw(u.raw),
+	})
+}
+
+// UnmarshalJSON unmarshals the token and restores the raw token response.
+func (u *Token) UnmarshalJSON(data []byte) error {
+	value := struct {
+		*tokenJSON
+		Raw json.RawMessage `json:"raw,omitempty"`
+	}{
+		tokenJSON: (*tokenJSON)(u),
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	u.raw = unmarshalRaw(value.Raw)
+	return nil
+}
+
 // GetToken gets the token by code and state.
-func GetToken(config *Config, code string, state string) (*Token, error) {
+func GetToken(config *Config, code string, state string, options ...Option) (*Token, error) {
 	token := &Token{}
+	opts := applyOptions(options)
 
 	oauth2ProviderTokenURL := config.TokenURL
-	oauth2ClientID := config.ClientID
-	oauth2ClientSecret := config.ClientSecret
 	oauth2RedirectURI := config.RedirectURI
 	//
 	oauth2AccessTokenAttributeName := config.AccessTokenAttributeName
@@ -41,20 +126,14 @@ func GetToken(config *Config, code string, state string) (*Token, error) {
 	if config.GetAccessTokenResponse != nil {
 		response, err = config.GetAccessTokenResponse(config, code, state)
 	} else {
-		response, err = fetch.Post(oauth2ProviderTokenURL, &fetch.Config{
-			Headers: map[string]string{
-				"Content-Type": "application/x-www-form-urlencoded",
-				"Accept":       "application/json",
-			},
-			Body: map[string]string{
-				"client_id":     oauth2ClientID,
-				"client_secret": oauth2ClientSecret,
-				"grant_type":    "authorization_code",
-				"redirect_uri":  oauth2RedirectURI,
-				"code":          code,
-				"state":         state,
-			},
-		})
+		form := config.resourceParams(opts)
+		form.Set("grant_type", "authorization_code")
+		form.Set("redirect_uri", oauth2RedirectURI)
+		form.Set("code", code)
+		form.Set("state", state)
+		headers := config.applyClientAuth(form)
+
+		response, err = postForm(config.httpClient(), oauth2ProviderTokenURL, headers, form)
 	}
 	if err != nil {
 		return nil, errors.New("get access token error by code (3): " + err.Error())
@@ -62,12 +141,16 @@ func GetToken(config *Config, code string, state string) (*Token, error) {
 
 	logger.Debugf("[oauth2][GetToken][token]: %s", response.String())
 
+	if tokenErr := parseTokenError(response); tokenErr != nil {
+		return nil, tokenErr
+	}
+
 	errorCode := response.Get("code").Int()
 	errorMessage := response.Get("message").String()
 	if errorCode == 5003002 {
 		return nil, errors.New("code is expired: " + errorMessage)
 	} else if errorCode != 0 {
-		return nil, errors.New("get access token error by code (3): " + err.Error())
+		return nil, fmt.Errorf("get access token error by code (3): %s (code: %d)", errorMessage, errorCode)
 	}
 
 	//
@@ -80,19 +163,28 @@ func GetToken(config *Config, code string, state string) (*Token, error) {
 	token.RefreshToken = refreshToken
 	token.ExpiresIn = expiresIn
 	token.TokenType = tokenType
+	token.IDToken = response.Get(config.IDTokenAttributeName).String()
+	token.Expiry = expiryFromExpiresIn(expiresIn)
+	token.Scopes = config.grantedScopes(response, opts)
+	if err := token.parseAuthorizationDetails(response); err != nil {
+		return nil, err
+	}
 
 	token.raw = response
 
 	return token, nil
 }
 
-// RefreshToken refresh the token by refresh token.
-func RefreshToken(config *Config, refreshTokenString string) (*Token, error) {
+// RefreshToken refresh the token by refresh token,
+// the token carries the new refresh token if rotated, or the old one if not,
+// and an invalid_grant TokenError (errors.Is ErrInvalidGrant) means the user should login again.
+//
+// Pass WithScopes with the scopes of the previous token to keep them when the provider omits the scope.
+func RefreshToken(config *Config, refreshTokenString string, options ...Option) (*Token, error) {
 	token := &Token{}
+	opts := applyOptions(options)
 
 	oauth2ProviderTokenURL := config.TokenURL
-	oauth2ClientID := config.ClientID
-	oauth2ClientSecret := config.ClientSecret
 	//
 	oauth2AccessTokenAttributeName := config.AccessTokenAttributeName
 	oauth2RefreshTokenAttributeName := config.RefreshTokenAttributeName
@@ -104,18 +196,12 @@ func RefreshToken(config *Config, refreshTokenString string) (*Token, error) {
 	if config.RefreshToken != nil {
 		response, err = config.RefreshToken(config, refreshTokenString)
 	} else {
-		response, err = fetch.Post(oauth2ProviderTokenURL, &fetch.Config{
-			Headers: map[string]string{
-				"Content-Type": "application/x-www-form-urlencoded",
-				"Accept":       "application/json",
-			},
-			Body: map[string]string{
-				"client_id":     oauth2ClientID,
-				"client_secret": oauth2ClientSecret,
-				"grant_type":    "refresh_token",
-				"refresh_token": refreshTokenString,
-			},
-		})
+		form := config.resourceParams(opts)
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", refreshTokenString)
+		headers := config.applyClientAuth(form)
+
+		response, err = postForm(config.httpClient(), oauth2ProviderTokenURL, headers, form)
 	}
 	if err != nil {
 		return nil, errors.New("get access token error by code (3): " + err.Error())
@@ -123,12 +209,16 @@ func RefreshToken(config *Config, refreshTokenString string) (*Token, error) {
 
 	logger.Debugf("[oauth2][RefreshToken][token]: %s", response.String())
 
+	if tokenErr := parseTokenError(response); tokenErr != nil {
+		return nil, tokenErr
+	}
+
 	errorCode := response.Get("code").Int()
 	errorMessage := response.Get("message").String()
 	if errorCode == 5003002 {
 		return nil, errors.New("code is expired: " + errorMessage)
 	} else if errorCode != 0 {
-		return nil, errors.New("get access token error by code (3): " + err.Error())
+		return nil, fmt.Errorf("get access token error by code (3): %s (code: %d)", errorMessage, errorCode)
 	}
 
 	//
@@ -137,10 +227,21 @@ func RefreshToken(config *Config, refreshTokenString string) (*Token, error) {
 	expiresIn := response.Get(oauth2ExpiresInAttributeName).Int()
 	tokenType := response.Get(oauth2TokenTypeAttributeName).String()
 
+	// the refresh token is not returned if it is not rotated
+	if refreshToken == "" {
+		refreshToken = refreshTokenString
+	}
+
 	token.AccessToken = accessToken
 	token.RefreshToken = refreshToken
 	token.ExpiresIn = expiresIn
 	token.TokenType = tokenType
+	token.IDToken = response.Get(config.IDTokenAttributeName).String()
+	token.Expiry = expiryFromExpiresIn(expiresIn)
+	token.Scopes = config.grantedScopes(response, opts)
+	if err := token.parseAuthorizationDetails(response); err != nil {
+		return nil, err
+	}
 
 	token.raw = response
 
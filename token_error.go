@@ -0,0 +1,51 @@
+package oauth2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-zoox/fetch"
+)
+
+// ErrInvalidGrant matches the invalid_grant TokenError by errors.Is,
+// such as the refresh token is expired, revoked or reused after rotation,
+// the user should login again instead of retrying.
+var ErrInvalidGrant = errors.New("oauth2: invalid_grant")
+
+// TokenError is the error response of the token endpoint (RFC 6749 section 5.2).
+type TokenError struct {
+	Code        string
+	Description string
+	URI         string
+	// Status is the http status of the response.
+	Status int
+}
+
+// Error returns the error message.
+func (e *TokenError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth2: token error %s", e.Code)
+	}
+
+	return fmt.Sprintf("oauth2: token error %s (%s)", e.Code, e.Description)
+}
+
+// Is matches ErrInvalidGrant for the invalid_grant error.
+func (e *TokenError) Is(target error) bool {
+	return target == ErrInvalidGrant && e.Code == "invalid_grant"
+}
+
+// parseTokenError returns the TokenError of the response, nil if it is not an error response.
+func parseTokenError(response *fetch.Response) *TokenError {
+	code := response.Get("error").String()
+	if code == "" {
+		return nil
+	}
+
+	return &TokenError{
+		Code:        code,
+		Description: response.Get("error_description").String(),
+		URI:         response.Get("error_uri").String(),
+		Status:      response.Status,
+	}
+}
@@ -0,0 +1,68 @@
+package oauth2
+
+import (
+	"sync"
+)
+
+// TokenSource is anything that can return a token.
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as TokenSource.
+type TokenSourceFunc func() (*Token, error)
+
+// Token returns the token by calling fn.
+func (fn TokenSourceFunc) Token() (*Token, error) {
+	return fn()
+}
+
+// reuseTokenSource caches the token until it expires.
+type reuseTokenSource struct {
+	sync.Mutex
+	current *Token
+	source  TokenSource
+}
+
+// ReuseTokenSource returns a TokenSource which repeatedly returns the same token
+// as long as it is valid, starting with current. When the cached token is invalid,
+// a new token is obtained from source.
+func ReuseTokenSource(current *Token, source TokenSource) TokenSource {
+	if rts, ok := source.(*reuseTokenSource); ok {
+		if current == nil {
+			return rts
+		}
+
+		source = rts.source
+	}
+
+	return &reuseTokenSource{
+		current: current,
+		source:  source,
+	}
+}
+
+// Token returns the cached token or fetches a new one when it is expired.
+func (s *reuseTokenSource) Token() (*Token, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.current.Valid() {
+		return s.current, nil
+	}
+
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.current = token
+	return token, nil
+}
+
+// StaticTokenSource returns a TokenSource that always returns the same token.
+func StaticTokenSource(token *Token) TokenSource {
+	return TokenSourceFunc(func() (*Token, error) {
+		return token, nil
+	})
+}
@@ -0,0 +1,259 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrTokenNotFound is returned by TokenStore.Get when there is no token of the key.
+var ErrTokenNotFound = errors.New("oauth2: token not found")
+
+// TokenKey is the key of the stored token.
+type TokenKey struct {
+	User     string
+	Provider string
+}
+
+// String returns the key as provider/user, which is only for messages,
+// use Encode to identify the key.
+func (k TokenKey) String() string {
+	return k.Provider + "/" + k.User
+}
+
+// Encode returns the unambiguous encoding of the key, the length-prefixed provider and user,
+// such as 6:github4:1234, which is used as the storage key and the associated data of encryption.
+func (k TokenKey) Encode() string {
+	return strconv.Itoa(len(k.Provider)) + ":" + k.Provider + strconv.Itoa(len(k.User)) + ":" + k.User
+}
+
+// TokenStore persists the tokens by user and provider.
+type TokenStore interface {
+	// Get gets the token, ErrTokenNotFound if not found.
+	Get(key TokenKey) (*Token, error)
+	Save(key TokenKey, token *Token) error
+	// Delete deletes the token, no error if not found.
+	Delete(key TokenKey) error
+}
+
+// memoryTokenStore is the in-memory TokenStore.
+type memoryTokenStore struct {
+	sync.RWMutex
+	tokens map[TokenKey]*Token
+}
+
+// NewMemoryTokenStore creates an in-memory TokenStore, the tokens are lost on restart.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{
+		tokens: map[TokenKey]*Token{},
+	}
+}
+
+// Get gets the token.
+func (s *memoryTokenStore) Get(key TokenKey) (*Token, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	copied := *token
+	return &copied, nil
+}
+
+// Save saves the token.
+func (s *memoryTokenStore) Save(key TokenKey, token *Token) error {
+	if token == nil {
+		return fmt.Errorf("oauth2: token of %s is nil", key)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	copied := *token
+	s.tokens[key] = &copied
+	return nil
+}
+
+// Delete deletes the token.
+func (s *memoryTokenStore) Delete(key TokenKey) error {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.tokens, key)
+	return nil
+}
+
+// TokenRefresher refreshes the token by refresh token, such as Client.
+type TokenRefresher interface {
+	RefreshToken(refreshToken string, options ...Option) (*Token, error)
+}
+
+// RefreshTokenSource returns a TokenSource which returns the token until it expires,
+// then refreshes it by its refresh token.
+func RefreshTokenSource(refresher TokenRefresher, token *Token) TokenSource {
+	return &storedTokenSource{
+		refresher: refresher,
+		current:   token,
+	}
+}
+
+// TokenSourceOption is the option of StoredTokenSource.
+type TokenSourceOption func(s *storedTokenSource)
+
+// WithRefreshLocker serializes the refreshes of the same key by the locker,
+// use a distributed RefreshLocker when the store is shared by many instances.
+func WithRefreshLocker(locker RefreshLocker) TokenSourceOption {
+	return func(s *storedTokenSource) {
+		if locker != nil {
+			s.locker = locker
+		}
+	}
+}
+
+// WithRefreshContext sets the context of the refreshes, which cancels waiting for the refresh lock.
+func WithRefreshContext(ctx context.Context) TokenSourceOption {
+	return func(s *storedTokenSource) {
+		if ctx != nil {
+			s.ctx = ctx
+		}
+	}
+}
+
+// StoredTokenSource returns a TokenSource of the stored token, which refreshes the token when it expires
+// and saves the refreshed token into the store, so rotated refresh tokens are persisted.
+//
+// The refreshes of the same key are serialized by the in-process locker, see WithRefreshLocker.
+// The stored token is deleted when the refresh token is rejected (invalid_grant),
+// and the error matches ErrInvalidGrant, so the user should login again.
+func StoredTokenSource(store TokenStore, key TokenKey, refresher TokenRefresher, options ...TokenSourceOption) TokenSource {
+	source := &storedTokenSource{
+		refresher: refresher,
+		store:     store,
+		key:       key,
+		locker:    defaultRefreshLocker,
+		ctx:       context.Background(),
+	}
+	for _, option := range options {
+		if option != nil {
+			option(source)
+		}
+	}
+
+	return source
+}
+
+type storedTokenSource struct {
+	sync.Mutex
+	refresher TokenRefresher
+	current   *Token
+	// store is optional
+	store TokenStore
+	key   TokenKey
+	// locker is optional
+	locker RefreshLocker
+	ctx    context.Context
+}
+
+// Token returns the valid token, refreshed if expired.
+func (s *storedTokenSource) Token() (*Token, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	// the store is only read when the cached token expires
+	if s.current.Valid() {
+		return s.current, nil
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	if s.current.Valid() {
+		return s.current, nil
+	}
+
+	if s.locker != nil {
+		unlock, err := s.locker.Lock(s.ctx, s.key.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: failed to lock the refresh of %s: %s", s.key, err)
+		}
+		defer unlock()
+
+		// the token may be refreshed by others while waiting for the lock
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+
+		if s.current.Valid() {
+			return s.current, nil
+		}
+	}
+
+	if s.current == nil || s.current.RefreshToken == "" {
+		return nil, fmt.Errorf("oauth2: token of %s is expired and has no refresh token", s.key)
+	}
+
+	refreshToken := s.current.RefreshToken
+	// the scopes are kept if the provider omits them
+	token, err := s.refresher.RefreshToken(refreshToken, WithScopes(s.current.Scopes...))
+	if err != nil {
+		if errors.Is(err, ErrInvalidGrant) {
+			return s.rejected(refreshToken, err)
+		}
+
+		return nil, err
+	}
+
+	// the refresh token is kept if not rotated
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	if s.store != nil {
+		if err := s.store.Save(s.key, token); err != nil {
+			return nil, err
+		}
+	}
+
+	s.current = token
+	return token, nil
+}
+
+// load reloads the current token from the store, which may be updated by other instances.
+func (s *storedTokenSource) load() error {
+	if s.store == nil {
+		return nil
+	}
+
+	token, err := s.store.Get(s.key)
+	if err != nil {
+		return err
+	}
+
+	s.current = token
+	return nil
+}
+
+// rejected handles the refresh token rejected by the provider,
+// which is expired, revoked or reused after rotation.
+func (s *storedTokenSource) rejected(refreshToken string, err error) (*Token, error) {
+	if s.store != nil {
+		// rotated by another instance without a shared locker
+		if token, errGet := s.store.Get(s.key); errGet == nil && token.RefreshToken != refreshToken && token.Valid() {
+			s.current = token
+			return token, nil
+		}
+
+		if errDelete := s.store.Delete(s.key); errDelete != nil {
+			return nil, errDelete
+		}
+	}
+
+	s.current = nil
+	return nil, fmt.Errorf("oauth2: refresh token of %s is rejected, login again: %w", s.key, err)
+}
@@ -0,0 +1,45 @@
+package tokenstore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-zoox/oauth2"
+)
+
+// encode marshals the token, encrypted and bound to the key if the keyring is given.
+func encode(keyring *Keyring, key oauth2.TokenKey, token *oauth2.Token) ([]byte, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyring == nil {
+		return data, nil
+	}
+
+	return keyring.Encrypt(data, []byte(key.Encode()))
+}
+
+// decode unmarshals the token, the plaintext tokens saved before the keyring is used are accepted.
+func decode(keyring *Keyring, key oauth2.TokenKey, data []byte) (*oauth2.Token, error) {
+	if IsEncrypted(data) {
+		if keyring == nil {
+			return nil, fmt.Errorf("oauth2: token is encrypted, but no keyring is given")
+		}
+
+		plaintext, err := keyring.Decrypt(data, []byte(key.Encode()))
+		if err != nil {
+			return nil, err
+		}
+
+		data = plaintext
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid stored token: %s", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,101 @@
+package tokenstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-zoox/oauth2"
+)
+
+// FileStore stores the tokens as files in the directory, one file per key.
+type FileStore struct {
+	Dir string
+	// Keyring encrypts the tokens, optional.
+	Keyring *Keyring
+	//
+	mu sync.Mutex
+}
+
+// NewFileStore creates the file store, the directory is created if not exists.
+func NewFileStore(dir string, keyring *Keyring) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("oauth2: failed to create token store dir(%s): %s", dir, err)
+	}
+
+	return &FileStore{
+		Dir:     dir,
+		Keyring: keyring,
+	}, nil
+}
+
+// Get gets the token.
+func (s *FileStore) Get(key oauth2.TokenKey) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, oauth2.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("oauth2: failed to read token of %s: %s", key, err)
+	}
+
+	return decode(s.Keyring, key, data)
+}
+
+// Save saves the token atomically.
+func (s *FileStore) Save(key oauth2.TokenKey, token *oauth2.Token) error {
+	data, err := encode(s.Keyring, key, token)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.CreateTemp(s.Dir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("oauth2: failed to save token of %s: %s", key, err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("oauth2: failed to save token of %s: %s", key, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("oauth2: failed to save token of %s: %s", key, err)
+	}
+
+	if err := os.Rename(file.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("oauth2: failed to save token of %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the token.
+func (s *FileStore) Delete(key oauth2.TokenKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("oauth2: failed to delete token of %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// path returns the file of the key, which is hashed to be a safe file name.
+func (s *FileStore) path(key oauth2.TokenKey) string {
+	sum := sha256.Sum256([]byte(key.Encode()))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".token")
+}
@@ -0,0 +1,133 @@
+package tokenstore
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-zoox/oauth2"
+)
+
+func testToken(accessToken string) *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: "refresh-" + accessToken,
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	for _, keyring := range []*Keyring{nil, testKeyring(t, "k1", "k1")} {
+		store, err := NewFileStore(t.TempDir(), keyring)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key := oauth2.TokenKey{Provider: "github", User: "1234"}
+		if _, err := store.Get(key); !errors.Is(err, oauth2.ErrTokenNotFound) {
+			t.Fatalf("err = %v, want ErrTokenNotFound", err)
+		}
+
+		token := testToken("a")
+		if err := store.Save(key, token); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := store.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.AccessToken != token.AccessToken || got.RefreshToken != token.RefreshToken || !got.Expiry.Equal(token.Expiry) {
+			t.Fatalf("got %+v, want %+v", got, token)
+		}
+
+		data, err := os.ReadFile(store.path(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if IsEncrypted(data) != (keyring != nil) {
+			t.Fatalf("unexpected stored token %s", data)
+		}
+
+		if err := store.Save(key, testToken("b")); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := store.Get(key); err != nil || got.AccessToken != "b" {
+			t.Fatalf("token is not replaced: %v %v", got, err)
+		}
+
+		if err := store.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete(key); err != nil {
+			t.Fatalf("delete of missing token: %s", err)
+		}
+		if _, err := store.Get(key); !errors.Is(err, oauth2.ErrTokenNotFound) {
+			t.Fatalf("err = %v, want ErrTokenNotFound", err)
+		}
+	}
+}
+
+func TestFileStoreKeysDoNotCollide(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), testKeyring(t, "k1", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// provider/user of both keys is a/b/c
+	a := oauth2.TokenKey{Provider: "a/b", User: "c"}
+	b := oauth2.TokenKey{Provider: "a", User: "b/c"}
+	if a.Encode() == b.Encode() || store.path(a) == store.path(b) {
+		t.Fatalf("keys %s and %s collide", a.Encode(), b.Encode())
+	}
+
+	if err := store.Save(a, testToken("a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(b); !errors.Is(err, oauth2.ErrTokenNotFound) {
+		t.Fatalf("err = %v, want ErrTokenNotFound", err)
+	}
+
+	// the encrypted token is bound to its key, and cannot be moved to another key
+	data, err := os.ReadFile(store.path(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.path(b), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(b); err == nil {
+		t.Fatal("the token of another key is decrypted")
+	}
+}
+
+func TestFileStorePlaintextMigration(t *testing.T) {
+	dir := t.TempDir()
+	key := oauth2.TokenKey{Provider: "github", User: "1234"}
+
+	plain, err := NewFileStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Save(key, testToken("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the plaintext tokens saved before the keyring is used are accepted
+	encrypted, err := NewFileStore(dir, testKeyring(t, "k1", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := encrypted.Get(key); err != nil || got.AccessToken != "a" {
+		t.Fatalf("plaintext token is not read: %v %v", got, err)
+	}
+
+	if err := encrypted.Save(key, testToken("b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Get(key); err == nil {
+		t.Fatal("the encrypted token is read without keyring")
+	}
+}
@@ -0,0 +1,163 @@
+package tokenstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix is the prefix of the encrypted tokens: v1.{key id}.{wrapped data key}.{ciphertext}.
+const encryptedPrefix = "v1."
+
+// Keyring is the envelope encryption of the stored tokens:
+// every token is encrypted by a random data key with AES-GCM,
+// and the data key is encrypted by the primary key.
+//
+// To rotate keys, add a new key as primary and keep the old keys to decrypt,
+// the tokens are encrypted by the new key when they are saved again.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates the keyring, the keys are 16, 24 or 32 bytes AES keys by key id.
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("oauth2: keyring primary key(%s) not found", primary)
+	}
+
+	keyring := &Keyring{
+		primary: primary,
+		keys:    map[string]cipher.AEAD{},
+	}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("oauth2: keyring key id(%s) must be non-empty without dot", id)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("oauth2: keyring key(%s): %s", id, err)
+		}
+
+		keyring.keys[id] = aead
+	}
+
+	return keyring, nil
+}
+
+// Primary returns the key id used to encrypt.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Encrypt encrypts the plaintext with a new data key, which is encrypted by the primary key,
+// the associated data (such as the token key) is authenticated, so the ciphertext cannot be moved to another key.
+func (k *Keyring) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(data, plaintext, additionalData(k.primary, associatedData))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.primary], dataKey, additionalData(k.primary, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(encryptedPrefix + k.primary + "." +
+		base64.RawURLEncoding.EncodeToString(wrapped) + "." +
+		base64.RawURLEncoding.EncodeToString(ciphertext)), nil
+}
+
+// Decrypt decrypts the ciphertext by the key of its key id, the associated data must be the one of Encrypt.
+func (k *Keyring) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(string(ciphertext), encryptedPrefix), ".")
+	if !IsEncrypted(ciphertext) || len(parts) != 3 {
+		return nil, fmt.Errorf("oauth2: malformed encrypted token")
+	}
+
+	key, ok := k.keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("oauth2: keyring key(%s) not found", parts[0])
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: malformed encrypted token: %s", err)
+	}
+
+	dataKey, err := open(key, wrapped, additionalData(parts[0], nil))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to decrypt data key by key(%s): %s", parts[0], err)
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: malformed encrypted token: %s", err)
+	}
+
+	plaintext, err := open(data, sealed, additionalData(parts[0], associatedData))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to decrypt token: %s", err)
+	}
+
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether the data is encrypted by a keyring.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedPrefix))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext as nonce + ciphertext, authenticated with the additional data.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the nonce + ciphertext, authenticated with the additional data.
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// additionalData binds the ciphertext to the key id and the associated data, such as the token key.
+func additionalData(id string, associatedData []byte) []byte {
+	return append([]byte(id+"."), associatedData...)
+}
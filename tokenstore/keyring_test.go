@@ -0,0 +1,212 @@
+package tokenstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func testKeyring(t *testing.T, primary string, ids ...string) *Keyring {
+	t.Helper()
+
+	keys := map[string][]byte{}
+	for i, id := range ids {
+		keys[id] = testKey(byte(i + 1))
+	}
+
+	keyring, err := NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keyring
+}
+
+// tamper flips a byte of the nth (0: key id, 1: wrapped data key, 2: ciphertext) part of the encrypted data.
+func tamper(t *testing.T, data []byte, n int) []byte {
+	t.Helper()
+
+	parts := strings.Split(strings.TrimPrefix(string(data), encryptedPrefix), ".")
+	raw, err := base64.RawURLEncoding.DecodeString(parts[n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 0x01
+	parts[n] = base64.RawURLEncoding.EncodeToString(raw)
+
+	return []byte(encryptedPrefix + strings.Join(parts, "."))
+}
+
+func TestKeyring(t *testing.T) {
+	plaintext := []byte(`{"access_token":"secret"}`)
+	associatedData := []byte("6:github4:1234")
+
+	keyring := testKeyring(t, "k1", "k1", "k2")
+	encrypted, err := keyring.Encrypt(plaintext, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) || !strings.HasPrefix(string(encrypted), "v1.k1.") {
+		t.Fatalf("unexpected encrypted token %s", encrypted)
+	}
+	if bytes.Contains(encrypted, []byte("secret")) {
+		t.Fatal("the plaintext is leaked")
+	}
+
+	cases := []struct {
+		name           string
+		keyring        *Keyring
+		data           []byte
+		associatedData []byte
+		want           string
+	}{
+		{
+			name:           "round trip",
+			keyring:        keyring,
+			data:           encrypted,
+			associatedData: associatedData,
+		},
+		{
+			name:           "other associated data",
+			keyring:        keyring,
+			data:           encrypted,
+			associatedData: []byte("6:github4:5678"),
+			want:           "failed to decrypt token",
+		},
+		{
+			name:           "empty associated data",
+			keyring:        keyring,
+			data:           encrypted,
+			associatedData: nil,
+			want:           "failed to decrypt token",
+		},
+		{
+			name:           "unknown key id",
+			keyring:        testKeyring(t, "k3", "k3"),
+			data:           encrypted,
+			associatedData: associatedData,
+			want:           "key(k1) not found",
+		},
+		{
+			name:           "same key id with another key",
+			keyring:        testKeyring(t, "k2", "k2", "k1"),
+			data:           encrypted,
+			associatedData: associatedData,
+			want:           "failed to decrypt data key",
+		},
+		{
+			name:           "key id is moved",
+			keyring:        keyring,
+			data:           []byte(strings.Replace(string(encrypted), "v1.k1.", "v1.k2.", 1)),
+			associatedData: associatedData,
+			want:           "failed to decrypt data key",
+		},
+		{
+			name:           "tampered data key",
+			keyring:        keyring,
+			data:           tamper(t, encrypted, 1),
+			associatedData: associatedData,
+			want:           "failed to decrypt data key",
+		},
+		{
+			name:           "tampered ciphertext",
+			keyring:        keyring,
+			data:           tamper(t, encrypted, 2),
+			associatedData: associatedData,
+			want:           "failed to decrypt token",
+		},
+		{
+			name:           "truncated",
+			keyring:        keyring,
+			data:           encrypted[:strings.LastIndex(string(encrypted), ".")],
+			associatedData: associatedData,
+			want:           "malformed encrypted token",
+		},
+		{
+			name:           "plaintext",
+			keyring:        keyring,
+			data:           plaintext,
+			associatedData: associatedData,
+			want:           "malformed encrypted token",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decrypted, err := c.keyring.Decrypt(c.data, c.associatedData)
+			if c.want == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Fatalf("decrypted = %s, want %s", decrypted, plaintext)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("err = %v, want %s", err, c.want)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	plaintext := []byte(`{"access_token":"secret"}`)
+	associatedData := []byte("6:github4:1234")
+
+	old := testKeyring(t, "k1", "k1")
+	encrypted, err := old.Encrypt(plaintext, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k2 is the new primary, k1 is kept to decrypt the old tokens
+	rotated := testKeyring(t, "k2", "k1", "k2")
+	decrypted, err := rotated.Decrypt(encrypted, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("decrypted = %s, want %s", decrypted, plaintext)
+	}
+
+	reencrypted, err := rotated.Encrypt(decrypted, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(reencrypted), "v1.k2.") {
+		t.Fatalf("the token is not encrypted by the new primary key: %s", reencrypted)
+	}
+
+	if _, err := old.Decrypt(reencrypted, associatedData); err == nil {
+		t.Fatal("the old keyring decrypts the token of the new primary key")
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	cases := []struct {
+		name    string
+		primary string
+		keys    map[string][]byte
+		want    string
+	}{
+		{"missing primary", "k2", map[string][]byte{"k1": testKey(1)}, "primary key(k2) not found"},
+		{"invalid key size", "k1", map[string][]byte{"k1": []byte("short")}, "keyring key(k1)"},
+		{"dot in key id", "k.1", map[string][]byte{"k.1": testKey(1)}, "without dot"},
+		{"empty key id", "k1", map[string][]byte{"k1": testKey(1), "": testKey(2)}, "must be non-empty"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := NewKeyring(c.primary, c.keys); err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("err = %v, want %s", err, c.want)
+			}
+		})
+	}
+}
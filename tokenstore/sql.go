@@ -0,0 +1,181 @@
+package tokenstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-zoox/oauth2"
+)
+
+// DefaultTable is the default table of SQLStore.
+const DefaultTable = "oauth2_tokens"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// QuestionPlaceholder is the placeholder of MySQL and SQLite, such as ?.
+func QuestionPlaceholder(n int) string {
+	return "?"
+}
+
+// DollarPlaceholder is the placeholder of PostgreSQL, such as $1.
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// SQLStore stores the tokens in a database/sql table:
+//
+//	CREATE TABLE oauth2_tokens (
+//	  provider VARCHAR(255) NOT NULL,
+//	  user_key VARCHAR(255) NOT NULL,
+//	  token TEXT NOT NULL,
+//	  PRIMARY KEY (provider, user_key)
+//	)
+type SQLStore struct {
+	DB *sql.DB
+	// Table is the table name, default: DefaultTable.
+	Table string
+	// Keyring encrypts the tokens, optional.
+	Keyring *Keyring
+	// Placeholder returns the nth (from 1) bind parameter, default: QuestionPlaceholder.
+	Placeholder func(n int) string
+}
+
+// NewSQLStore creates the sql store, use CreateTable to create the table if not exists.
+func NewSQLStore(db *sql.DB, table string, keyring *Keyring) (*SQLStore, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("oauth2: invalid token store table name %s", table)
+	}
+
+	return &SQLStore{
+		DB:      db,
+		Table:   table,
+		Keyring: keyring,
+	}, nil
+}
+
+// CreateTable creates the table if not exists.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  provider VARCHAR(255) NOT NULL,
+  user_key VARCHAR(255) NOT NULL,
+  token TEXT NOT NULL,
+  PRIMARY KEY (provider, user_key)
+)`, s.table()))
+	if err != nil {
+		return fmt.Errorf("oauth2: failed to create token store table %s: %s", s.table(), err)
+	}
+
+	return nil
+}
+
+// Get gets the token.
+func (s *SQLStore) Get(key oauth2.TokenKey) (*oauth2.Token, error) {
+	var data string
+	err := s.DB.QueryRow(
+		fmt.Sprintf("SELECT token FROM %s WHERE provider = %s AND user_key = %s", s.table(), s.placeholder(1), s.placeholder(2)),
+		key.Provider, key.User,
+	).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauth2.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("oauth2: failed to get token of %s: %s", key, err)
+	}
+
+	return decode(s.Keyring, key, []byte(data))
+}
+
+// Save saves the token, updating the previous one or inserting if not found,
+// which is portable across databases and safe for concurrent saves of the same key.
+func (s *SQLStore) Save(key oauth2.TokenKey, token *oauth2.Token) error {
+	data, err := encode(s.Keyring, key, token)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.update(key, string(data))
+	if err != nil {
+		return fmt.Errorf("oauth2: failed to save token of %s: %s", key, err)
+	}
+	if updated {
+		return nil
+	}
+
+	_, errInsert := s.DB.Exec(
+		fmt.Sprintf("INSERT INTO %s (provider, user_key, token) VALUES (%s, %s, %s)", s.table(), s.placeholder(1), s.placeholder(2), s.placeholder(3)),
+		key.Provider, key.User, string(data),
+	)
+	if errInsert == nil {
+		return nil
+	}
+
+	// inserted by a concurrent save, update it again
+	if updated, err := s.update(key, string(data)); err == nil && updated {
+		return nil
+	}
+
+	return fmt.Errorf("oauth2: failed to save token of %s: %s", key, errInsert)
+}
+
+// update updates the token of the key, and reports whether the row exists.
+func (s *SQLStore) update(key oauth2.TokenKey, data string) (bool, error) {
+	result, err := s.DB.Exec(
+		fmt.Sprintf("UPDATE %s SET token = %s WHERE provider = %s AND user_key = %s", s.table(), s.placeholder(1), s.placeholder(2), s.placeholder(3)),
+		data, key.Provider, key.User,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows != 0 {
+		return true, nil
+	}
+
+	// MySQL reports the changed rows, which is 0 if the token is the same
+	var exists int
+	err = s.DB.QueryRow(
+		fmt.Sprintf("SELECT 1 FROM %s WHERE provider = %s AND user_key = %s", s.table(), s.placeholder(1), s.placeholder(2)),
+		key.Provider, key.User,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Delete deletes the token.
+func (s *SQLStore) Delete(key oauth2.TokenKey) error {
+	if _, err := s.DB.Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE provider = %s AND user_key = %s", s.table(), s.placeholder(1), s.placeholder(2)),
+		key.Provider, key.User,
+	); err != nil {
+		return fmt.Errorf("oauth2: failed to delete token of %s: %s", key, err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) table() string {
+	if s.Table == "" {
+		return DefaultTable
+	}
+
+	return s.Table
+}
+
+func (s *SQLStore) placeholder(n int) string {
+	if s.Placeholder == nil {
+		return QuestionPlaceholder(n)
+	}
+
+	return s.Placeholder(n)
+}
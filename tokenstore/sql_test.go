@@ -0,0 +1,307 @@
+package tokenstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-zoox/oauth2"
+)
+
+// fakeDB is an in-memory database/sql driver of the token table, which records the statements.
+type fakeDB struct {
+	mu   sync.Mutex
+	rows map[[2]string]string
+	// statements are the kinds of the executed statements, such as UPDATE and INSERT.
+	statements []string
+	// changedRows reports the changed rows of UPDATE like MySQL, 0 if the token is the same.
+	changedRows bool
+	// beforeInsert is called before INSERT, such as a concurrent save.
+	beforeInsert func()
+}
+
+func newFakeDB() (*fakeDB, *sql.DB) {
+	db := &fakeDB{
+		rows: map[[2]string]string{},
+	}
+
+	return db, sql.OpenDB(db)
+}
+
+func (db *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: db}, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+func (db *fakeDB) reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.statements = nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) kind() string {
+	switch {
+	case strings.HasPrefix(s.query, "SELECT token"):
+		return "SELECT"
+	case strings.HasPrefix(s.query, "SELECT 1"):
+		return "EXISTS"
+	default:
+		return strings.Fields(s.query)[0]
+	}
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	kind := s.kind()
+	if kind == "INSERT" && s.db.beforeInsert != nil {
+		s.db.beforeInsert()
+	}
+
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.statements = append(db.statements, kind)
+	switch kind {
+	case "INSERT":
+		key := [2]string{args[0].(string), args[1].(string)}
+		if _, ok := db.rows[key]; ok {
+			return nil, errors.New("duplicate key")
+		}
+
+		db.rows[key] = args[2].(string)
+		return driver.RowsAffected(1), nil
+	case "UPDATE":
+		key := [2]string{args[1].(string), args[2].(string)}
+		current, ok := db.rows[key]
+		if !ok || (db.changedRows && current == args[0].(string)) {
+			return driver.RowsAffected(0), nil
+		}
+
+		db.rows[key] = args[0].(string)
+		return driver.RowsAffected(1), nil
+	case "DELETE":
+		key := [2]string{args[0].(string), args[1].(string)}
+		delete(db.rows, key)
+		return driver.RowsAffected(1), nil
+	default:
+		return nil, errors.New("unexpected exec " + s.query)
+	}
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	kind := s.kind()
+	db.statements = append(db.statements, kind)
+
+	rows := &fakeRows{}
+	if value, ok := db.rows[[2]string{args[0].(string), args[1].(string)}]; ok {
+		if kind == "EXISTS" {
+			rows.values = []driver.Value{int64(1)}
+		} else {
+			rows.values = []driver.Value{value}
+		}
+	}
+
+	return rows, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done || r.values == nil {
+		return io.EOF
+	}
+
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+func TestSQLStoreSave(t *testing.T) {
+	key := oauth2.TokenKey{Provider: "github", User: "1234"}
+
+	cases := []struct {
+		name        string
+		existing    bool
+		changedRows bool
+		concurrent  bool
+		token       *oauth2.Token
+		want        []string
+	}{
+		{
+			name:  "insert",
+			token: testToken("a"),
+			want:  []string{"UPDATE", "EXISTS", "INSERT"},
+		},
+		{
+			name:     "update",
+			existing: true,
+			token:    testToken("b"),
+			want:     []string{"UPDATE"},
+		},
+		{
+			name:        "update the same token with changed rows",
+			existing:    true,
+			changedRows: true,
+			token:       testToken("a"),
+			want:        []string{"UPDATE", "EXISTS"},
+		},
+		{
+			name:       "inserted by a concurrent save",
+			concurrent: true,
+			token:      testToken("b"),
+			want:       []string{"UPDATE", "EXISTS", "INSERT", "UPDATE"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, sqlDB := newFakeDB()
+			defer sqlDB.Close()
+
+			store, err := NewSQLStore(sqlDB, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			existing := testToken("a")
+			existing.Expiry = c.token.Expiry
+			if c.existing {
+				if err := store.Save(key, existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if c.concurrent {
+				db.beforeInsert = func() {
+					db.beforeInsert = nil
+					if err := store.Save(key, existing); err != nil {
+						t.Error(err)
+					}
+				}
+			}
+
+			db.changedRows = c.changedRows
+			db.reset()
+			if err := store.Save(key, c.token); err != nil {
+				t.Fatal(err)
+			}
+
+			statements := db.statements
+			if c.concurrent {
+				// the statements of the concurrent save are recorded before INSERT
+				statements = append(statements[:2:2], statements[len(statements)-2:]...)
+			}
+			if !reflect.DeepEqual(statements, c.want) {
+				t.Errorf("statements = %v, want %v", statements, c.want)
+			}
+
+			got, err := store.Get(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.AccessToken != c.token.AccessToken {
+				t.Errorf("access token = %s, want %s", got.AccessToken, c.token.AccessToken)
+			}
+		})
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	_, sqlDB := newFakeDB()
+	defer sqlDB.Close()
+
+	if _, err := NewSQLStore(sqlDB, "tokens; DROP TABLE users", nil); err == nil {
+		t.Fatal("invalid table name is accepted")
+	}
+
+	store, err := NewSQLStore(sqlDB, "auth.tokens", testKeyring(t, "k1", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := oauth2.TokenKey{Provider: "github", User: "1234"}
+	b := oauth2.TokenKey{Provider: "github", User: "5678"}
+	if _, err := store.Get(a); !errors.Is(err, oauth2.ErrTokenNotFound) {
+		t.Fatalf("err = %v, want ErrTokenNotFound", err)
+	}
+
+	if err := store.Save(a, testToken("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	var data string
+	if err := sqlDB.QueryRow("SELECT token FROM x WHERE provider = ? AND user_key = ?", a.Provider, a.User).Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted([]byte(data)) {
+		t.Fatalf("token is not encrypted: %s", data)
+	}
+
+	// the encrypted token cannot be moved to another key
+	if _, err := sqlDB.Exec("INSERT INTO x (provider, user_key, token) VALUES (?, ?, ?)", b.Provider, b.User, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(b); err == nil {
+		t.Fatal("the token of another key is decrypted")
+	}
+
+	if err := store.Delete(a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(a); !errors.Is(err, oauth2.ErrTokenNotFound) {
+		t.Fatalf("err = %v, want ErrTokenNotFound", err)
+	}
+}
@@ -0,0 +1,155 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenKeyEncode(t *testing.T) {
+	keys := []TokenKey{
+		{Provider: "a/b", User: "c"},
+		{Provider: "a", User: "b/c"},
+		{Provider: "a", User: "1:b"},
+		{Provider: "a1:b", User: ""},
+		{Provider: "", User: "a"},
+		{Provider: "a", User: ""},
+	}
+
+	encoded := map[string]TokenKey{}
+	for _, key := range keys {
+		if other, ok := encoded[key.Encode()]; ok {
+			t.Errorf("%+v and %+v collide as %s", key, other, key.Encode())
+		}
+		encoded[key.Encode()] = key
+	}
+
+	if got := (TokenKey{Provider: "github", User: "1234"}).Encode(); got != "6:github4:1234" {
+		t.Errorf("Encode() = %s", got)
+	}
+}
+
+type testRefresher struct {
+	calls int
+	err   error
+}
+
+func (r *testRefresher) RefreshToken(refreshToken string, options ...Option) (*Token, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &Token{
+		AccessToken:  "refreshed",
+		RefreshToken: "rotated",
+		Expiry:       time.Now().Add(time.Hour),
+	}, nil
+}
+
+type testLocker struct {
+	keys []string
+	ctx  context.Context
+}
+
+func (l *testLocker) Lock(ctx context.Context, key string) (func(), error) {
+	l.keys = append(l.keys, key)
+	l.ctx = ctx
+	return func() {}, nil
+}
+
+func expiredToken() *Token {
+	return &Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+}
+
+func TestStoredTokenSource(t *testing.T) {
+	key := TokenKey{Provider: "github", User: "1234"}
+	store := NewMemoryTokenStore()
+	if err := store.Save(key, expiredToken()); err != nil {
+		t.Fatal(err)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	locker := &testLocker{}
+	refresher := &testRefresher{}
+
+	source := StoredTokenSource(store, key, refresher, WithRefreshLocker(locker), WithRefreshContext(ctx))
+	token, err := source.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "refreshed" {
+		t.Fatalf("access token = %s, want refreshed", token.AccessToken)
+	}
+
+	if len(locker.keys) != 1 || locker.keys[0] != key.Encode() {
+		t.Errorf("locked keys = %v, want %s", locker.keys, key.Encode())
+	}
+	if locker.ctx != ctx {
+		t.Error("the context of the caller is not passed to the locker")
+	}
+
+	stored, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RefreshToken != "rotated" {
+		t.Errorf("the rotated refresh token is not saved: %s", stored.RefreshToken)
+	}
+
+	// the valid token is not refreshed again
+	if _, err := source.Token(); err != nil || refresher.calls != 1 {
+		t.Errorf("refreshed %d times, err: %v", refresher.calls, err)
+	}
+}
+
+func TestStoredTokenSourceContext(t *testing.T) {
+	key := TokenKey{Provider: "github", User: "1234"}
+	store := NewMemoryTokenStore()
+	if err := store.Save(key, expiredToken()); err != nil {
+		t.Fatal(err)
+	}
+
+	locker := NewRefreshLocker()
+	unlock, err := locker.Lock(context.Background(), key.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	refresher := &testRefresher{}
+	source := StoredTokenSource(store, key, refresher, WithRefreshLocker(locker), WithRefreshContext(ctx))
+	if _, err := source.Token(); err == nil {
+		t.Fatal("the token is refreshed while another refresh holds the lock")
+	}
+	if refresher.calls != 0 {
+		t.Errorf("refreshed %d times, want 0", refresher.calls)
+	}
+}
+
+func TestStoredTokenSourceInvalidGrant(t *testing.T) {
+	key := TokenKey{Provider: "github", User: "1234"}
+	store := NewMemoryTokenStore()
+	if err := store.Save(key, expiredToken()); err != nil {
+		t.Fatal(err)
+	}
+
+	refresher := &testRefresher{err: &TokenError{Code: "invalid_grant"}}
+	_, err := StoredTokenSource(store, key, refresher).Token()
+	if !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("err = %v, want ErrInvalidGrant", err)
+	}
+
+	if _, err := store.Get(key); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("the rejected token is not deleted: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package oauth2
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Transport is a http.RoundTripper that authenticates requests
+// with the access token from Source.
+type Transport struct {
+	Source TokenSource
+	// Base is the underlying round tripper, default: http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// RoundTrip authorizes and sends the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Source == nil {
+		closeRequestBody(req)
+		return nil, errors.New("oauth2: transport token source is nil")
+	}
+
+	token, err := t.Source.Token()
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	tokenType := token.TokenType
+	if tokenType == "" || tokenType == "bearer" {
+		tokenType = "Bearer"
+	}
+
+	// the request must not be modified, see http.RoundTripper
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+
+	return t.base().RoundTrip(authorized)
+}
+
+// closeRequestBody closes the request body when the request is not sent, see http.RoundTripper.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+// NewHTTPClient creates a http client which authenticates all requests with the tokens from source.
+func NewHTTPClient(source TokenSource) *http.Client {
+	return &http.Client{
+		Transport: &Transport{
+			Source: ReuseTokenSource(nil, source),
+		},
+	}
+}
@@ -1,14 +1,17 @@
 package oauth2
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/go-zoox/fetch"
 	"github.com/go-zoox/logger"
 	"github.com/tidwall/gjson"
 )
 
-// User is the oauth2 user.
+// User is the oauth2 user, with the OpenID Connect standard claims.
 type User struct {
 	ID          string   `json:"id"`
 	Username    string   `json:"username"`
@@ -17,15 +20,123 @@ type User struct {
 	Nickname    string   `json:"nickname"`
 	Groups      []string `json:"groups"`
 	Permissions []string `json:"permissions"`
+	// Roles are the application roles granted by Config.RoleMapping.
+	Roles []string `json:"roles,omitempty"`
+	//
+	Name          string   `json:"name,omitempty"`
+	GivenName     string   `json:"given_name,omitempty"`
+	FamilyName    string   `json:"family_name,omitempty"`
+	MiddleName    string   `json:"middle_name,omitempty"`
+	Profile       string   `json:"profile,omitempty"`
+	Homepage      string   `json:"homepage,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Gender        string   `json:"gender,omitempty"`
+	Birthdate     string   `json:"birthdate,omitempty"`
+	ZoneInfo      string   `json:"zoneinfo,omitempty"`
+	Locale        string   `json:"locale,omitempty"`
+	Phone         string   `json:"phone_number,omitempty"`
+	PhoneVerified bool     `json:"phone_number_verified,omitempty"`
+	Address       *Address `json:"address,omitempty"`
+	// UpdatedAt is the time the user profile was last updated, nil if unknown.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Provider is the name of the provider, such as GitHub.
+	Provider string `json:"provider,omitempty"`
+	// Claims are all the fields of the user info response, restored from raw in JSON.
+	Claims map[string]any `json:"-"`
 
 	raw *fetch.Response
 }
 
+// Address is the OpenID Connect address claim.
+type Address struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"street_address,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
 // Raw gets raw data with *fetch.Response.
 func (u *User) Raw() *fetch.Response {
 	return u.raw
 }
 
+// Claim gets the claim by gjson path, such as email or address.country, nil if not found.
+func (u *User) Claim(path string) any {
+	if u.raw != nil {
+		return u.raw.Get(path).Value()
+	}
+
+	data, err := json.Marshal(u.Claims)
+	if err != nil {
+		return nil
+	}
+
+	return gjson.GetBytes(data, path).Value()
+}
+
+type userJSON User
+
+// MarshalJSON marshals the user with the raw user info response.
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		userJSON
+		Raw json.RawMessage `json:"raw,omitempty"`
+	}{
+		userJSON: userJSON(u),
+		Raw:      marshalRaw(u.raw),
+	})
+}
+
+// UnmarshalJSON unmarshals the user and restores the raw user info response.
+func (u *User) UnmarshalJSON(data []byte) error {
+	value := struct {
+		*userJSON
+		Raw json.RawMessage `json:"raw,omitempty"`
+	}{
+		userJSON: (*userJSON)(u),
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	u.raw = unmarshalRaw(value.Raw)
+	u.Claims = parseClaims(u.raw)
+	return nil
+}
+
+// marshalRaw marshals the raw response body, JSON body as is and others as string.
+func marshalRaw(response *fetch.Response) json.RawMessage {
+	if response == nil || len(response.Body) == 0 {
+		return nil
+	}
+
+	if json.Valid(response.Body) {
+		return response.Body
+	}
+
+	data, _ := json.Marshal(string(response.Body))
+	return data
+}
+
+// unmarshalRaw restores the raw response from the body marshaled by marshalRaw.
+func unmarshalRaw(data json.RawMessage) *fetch.Response {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	body := []byte(data)
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		body = []byte(text)
+	}
+
+	return &fetch.Response{
+		Body: body,
+	}
+}
+
 // GetUser gets the user by token.
 func GetUser(config *Config, token *Token, code string) (*User, error) {
 	user := &User{}
@@ -60,13 +171,6 @@ func GetUser(config *Config, token *Token, code string) (*User, error) {
 	user.Avatar = response.Get(config.AvatarAttributeName).String()
 	user.Permissions = make([]string, 0)
 
-	if user.Username == "" {
-		user.Username = user.Email
-	}
-	if user.Username == "" {
-		user.Username = user.ID
-	}
-
 	permissionsResult := response.Get(config.PermissionsAttributeName)
 	permissionsResult.ForEach(func(key, value gjson.Result) bool {
 		user.Permissions = append(user.Permissions, value.String())
@@ -79,7 +183,88 @@ func GetUser(config *Config, token *Token, code string) (*User, error) {
 		return true
 	})
 
+	user.Name = response.Get(config.NameAttributeName).String()
+	user.GivenName = response.Get(config.GivenNameAttributeName).String()
+	user.FamilyName = response.Get(config.FamilyNameAttributeName).String()
+	user.MiddleName = response.Get(config.MiddleNameAttributeName).String()
+	user.Profile = response.Get(config.ProfileAttributeName).String()
+	user.Homepage = response.Get(config.HomepageAttributeName).String()
+	user.EmailVerified = response.Get(config.EmailVerifiedAttributeName).Bool()
+	user.Gender = response.Get(config.GenderAttributeName).String()
+	user.Birthdate = response.Get(config.BirthdateAttributeName).String()
+	user.ZoneInfo = response.Get(config.ZoneInfoAttributeName).String()
+	user.Locale = response.Get(config.LocaleAttributeName).String()
+	user.Phone = response.Get(config.PhoneAttributeName).String()
+	user.PhoneVerified = response.Get(config.PhoneVerifiedAttributeName).Bool()
+	user.UpdatedAt = parseTimeClaim(response.Get(config.UpdatedAtAttributeName))
+	user.Provider = config.Name
+
+	if address := response.Get(config.AddressAttributeName); address.IsObject() {
+		user.Address = &Address{}
+		if err := json.Unmarshal([]byte(address.Raw), user.Address); err != nil {
+			user.Address = nil
+		}
+	} else if address.String() != "" {
+		user.Address = &Address{Formatted: address.String()}
+	}
+
+	applyClaimMappings(user, response, config.ClaimMappings)
+
+	if user.Username == "" {
+		user.Username = user.Email
+	}
+	if user.Username == "" {
+		user.Username = user.ID
+	}
+
+	user.Claims = parseClaims(response)
 	user.raw = response
 
+	if config.EnrichUser != nil {
+		if err := config.EnrichUser(config, token, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.RoleMapping != nil {
+		user.Roles = config.RoleMapping.Roles(user)
+		if config.RoleMapping.RequireRole && len(user.Roles) == 0 {
+			return nil, fmt.Errorf("%w, no role is granted", ErrUserAccessDenied)
+		}
+	}
+
 	return user, nil
 }
+
+// parseClaims parses all the fields of the JSON object response.
+func parseClaims(response *fetch.Response) map[string]any {
+	if response == nil {
+		return nil
+	}
+
+	claims := map[string]any{}
+	if err := json.Unmarshal(response.Body, &claims); err != nil {
+		return nil
+	}
+
+	return claims
+}
+
+// parseTimeClaim parses the time of seconds since epoch or RFC 3339, such as updated_at.
+func parseTimeClaim(result gjson.Result) *time.Time {
+	var t time.Time
+	switch result.Type {
+	case gjson.Number:
+		t = time.Unix(result.Int(), 0)
+	case gjson.String:
+		parsed, err := time.Parse(time.RFC3339, result.String())
+		if err != nil {
+			return nil
+		}
+		t = parsed
+	default:
+		return nil
+	}
+
+	return &t
+}
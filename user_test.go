@@ -0,0 +1,71 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestUserMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(User{ID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "updated_at") || strings.Contains(string(data), "0001-01-01") {
+		t.Errorf("unknown updated_at is marshaled: %s", data)
+	}
+
+	updatedAt := time.Unix(1700000000, 0).UTC()
+	user := User{
+		ID:            "1",
+		Phone:         "+1 555 0100",
+		PhoneVerified: true,
+		UpdatedAt:     &updatedAt,
+	}
+	data, err = json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := gjson.ParseBytes(data)
+	if result.Get("phone_number").String() != user.Phone || !result.Get("phone_number_verified").Bool() {
+		t.Errorf("phone is not marshaled as the OpenID Connect claims: %s", data)
+	}
+	if result.Get("updated_at").String() != "2023-11-14T22:13:20Z" {
+		t.Errorf("unexpected updated_at: %s", data)
+	}
+
+	restored := &User{}
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Phone != user.Phone || restored.UpdatedAt == nil || !restored.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("unexpected restored user %+v", restored)
+	}
+}
+
+func TestParseTimeClaim(t *testing.T) {
+	cases := []struct {
+		claims string
+		want   int64
+	}{
+		{`{"updated_at": 1700000000}`, 1700000000},
+		{`{"updated_at": "2023-11-14T22:13:20Z"}`, 1700000000},
+		{`{"updated_at": "yesterday"}`, 0},
+		{`{"updated_at": null}`, 0},
+		{`{}`, 0},
+	}
+
+	for _, c := range cases {
+		got := parseTimeClaim(gjson.Get(c.claims, "updated_at"))
+		switch {
+		case c.want == 0 && got != nil:
+			t.Errorf("parseTimeClaim(%s) = %s, want nil", c.claims, got)
+		case c.want != 0 && (got == nil || got.Unix() != c.want):
+			t.Errorf("parseTimeClaim(%s) = %v, want %d", c.claims, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package oauth2
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Validate validates the config and reports all the problems together,
+// it can be used without creating a client.
+func (oac *Config) Validate() error {
+	errs := Errors{}
+
+	required := []struct {
+		value string
+		err   error
+	}{
+		{oac.AuthURL, ErrConfigAuthURLEmpty},
+		{oac.TokenURL, ErrConfigTokenURLEmpty},
+		{oac.RedirectURI, ErrConfigRedirectURIEmpty},
+		{oac.ClientID, ErrConfigClientIDEmpty},
+	}
+	// the user info is got by GetUserResponse, such as from the id_token
+	if oac.GetUserResponse == nil {
+		required = append(required, struct {
+			value string
+			err   error
+		}{oac.UserInfoURL, ErrConfigUserInfoURLEmpty})
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errs.Add(r.err)
+		}
+	}
+
+	urls := []struct {
+		field string
+		value string
+	}{
+		{"auth url", oac.AuthURL},
+		{"token url", oac.TokenURL},
+		{"user info url", oac.UserInfoURL},
+		{"refresh token url", oac.RefershTokenURL},
+		{"logout url", oac.LogoutURL},
+		{"register url", oac.RegisterURL},
+		{"base url", oac.BaseURL},
+		{"api base url", oac.APIBaseURL},
+		{"issuer", oac.Issuer},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			continue
+		}
+
+		if err := validateEndpointURL(u.value); err != nil {
+			errs.Add(fmt.Errorf("oauth2: config %s(%s) %s", u.field, u.value, err))
+		}
+	}
+
+	redirectURIs := []struct {
+		field string
+		value string
+	}{
+		{"redirect uri", oac.RedirectURI},
+		{"post logout redirect uri", oac.PostLogoutRedirectURI},
+	}
+	for _, u := range redirectURIs {
+		if u.value == "" {
+			continue
+		}
+
+		if err := validateRedirectURI(u.value); err != nil {
+			errs.Add(fmt.Errorf("oauth2: config %s(%s) %s", u.field, u.value, err))
+		}
+	}
+
+	switch oac.AuthStyle {
+	case "", AuthStyleInParams, AuthStyleInHeader:
+		if oac.ClientSecret == "" {
+			errs.Add(ErrConfigClientSecretEmpty)
+		}
+	case AuthStyleNone:
+		if oac.ClientSecret != "" {
+			errs.Add(ErrConfigPublicClientSecret)
+		}
+	default:
+		errs.Add(fmt.Errorf("oauth2: config auth style(%s) is unknown", oac.AuthStyle))
+	}
+
+	attributes := oac.attributeNames()
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs.Add(validateAttributeName(name, attributes[name]))
+	}
+
+	if len(oac.AuthorizationDetails) != 0 {
+		if _, err := encodeAuthorizationDetails(oac.AuthorizationDetails); err != nil {
+			errs.Add(err)
+		}
+	}
+
+	errs.Add(validateClaimMappings("config", oac.ClaimMappings))
+
+	if oac.RoleMapping != nil {
+		errs.Add(oac.RoleMapping.Validate())
+	}
+
+	return errs.Err()
+}
+
+// attributeNames returns the attribute mappings of the config.
+func (oac *Config) attributeNames() map[string]string {
+	names := map[string]string{}
+	for group, attributes := range definitionAttributes(oac) {
+		for key, value := range attributes {
+			names[group+"."+key] = *value
+		}
+	}
+
+	return names
+}
+
+// validateAttributeName validates the attribute mapping is a gjson path.
+func validateAttributeName(name, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if strings.TrimSpace(value) != value || strings.ContainsAny(value, " \t\r\n") {
+		return fmt.Errorf("oauth2: config attribute mapping %s(%q) must not contain whitespace", name, value)
+	}
+
+	if strings.HasPrefix(value, ".") || strings.HasSuffix(value, ".") || strings.Contains(value, "..") {
+		return fmt.Errorf("oauth2: config attribute mapping %s(%q) has an empty path segment", name, value)
+	}
+
+	return nil
+}
+
+// validateEndpointURL validates the url is absolute, and https is required except loopback.
+func validateEndpointURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("is invalid: %s", err)
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("must be an absolute url")
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !isLoopback(u.Hostname()) {
+			return fmt.Errorf("must use https, http is only allowed for loopback")
+		}
+	default:
+		return fmt.Errorf("has unsupported scheme %s", u.Scheme)
+	}
+
+	return nil
+}
+
+// validateRedirectURI validates the redirect uri shape (RFC 6749 3.1.2 and RFC 8252),
+// which must be absolute without fragment. http is only allowed for loopback,
+// and private-use schemes (such as com.example.app:/callback) are allowed for native apps.
+func validateRedirectURI(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("is invalid: %s", err)
+	}
+
+	if !u.IsAbs() {
+		return fmt.Errorf("must be an absolute uri")
+	}
+
+	if u.Fragment != "" || strings.Contains(value, "#") {
+		return fmt.Errorf("must not contain a fragment")
+	}
+
+	switch u.Scheme {
+	case "https", "http":
+		if u.Host == "" {
+			return fmt.Errorf("host is empty")
+		}
+
+		if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+			return fmt.Errorf("must use https, http is only allowed for loopback")
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			return fmt.Errorf("private-use scheme must be a reverse domain name, such as com.example.app")
+		}
+	}
+
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,193 @@
+package oauth2
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validTestConfig() *Config {
+	return &Config{
+		AuthURL:      "https://idp.example.com/authorize",
+		TokenURL:     "https://idp.example.com/token",
+		UserInfoURL:  "https://idp.example.com/userinfo",
+		RedirectURI:  "https://app.example.com/callback",
+		ClientID:     "client",
+		ClientSecret: "secret",
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		modify  func(cfg *Config)
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "http endpoint",
+			modify: func(cfg *Config) { cfg.TokenURL = "http://idp.example.com/token" },
+			want:   "must use https",
+		},
+		{
+			name:   "http loopback endpoint",
+			modify: func(cfg *Config) { cfg.TokenURL = "http://localhost:8080/token" },
+		},
+		{
+			name:   "http loopback ip endpoint",
+			modify: func(cfg *Config) { cfg.AuthURL = "http://127.0.0.1:8080/authorize" },
+		},
+		{
+			name:   "http ipv6 loopback endpoint",
+			modify: func(cfg *Config) { cfg.UserInfoURL = "http://[::1]:8080/userinfo" },
+		},
+		{
+			name:   "relative endpoint",
+			modify: func(cfg *Config) { cfg.UserInfoURL = "/userinfo" },
+			want:   "must be an absolute url",
+		},
+		{
+			name:   "unsupported endpoint scheme",
+			modify: func(cfg *Config) { cfg.AuthURL = "ftp://idp.example.com/authorize" },
+			want:   "unsupported scheme",
+		},
+		{
+			name:   "http redirect uri",
+			modify: func(cfg *Config) { cfg.RedirectURI = "http://app.example.com/callback" },
+			want:   "must use https",
+		},
+		{
+			name:   "http loopback redirect uri",
+			modify: func(cfg *Config) { cfg.RedirectURI = "http://127.0.0.1:3000/callback" },
+		},
+		{
+			name:   "redirect uri fragment",
+			modify: func(cfg *Config) { cfg.RedirectURI = "https://app.example.com/callback#done" },
+			want:   "must not contain a fragment",
+		},
+		{
+			name:   "redirect uri empty fragment",
+			modify: func(cfg *Config) { cfg.RedirectURI = "https://app.example.com/callback#" },
+			want:   "must not contain a fragment",
+		},
+		{
+			name:   "post logout redirect uri fragment",
+			modify: func(cfg *Config) { cfg.PostLogoutRedirectURI = "https://app.example.com/#logout" },
+			want:   "post logout redirect uri",
+		},
+		{
+			name:   "private-use scheme redirect uri",
+			modify: func(cfg *Config) { cfg.RedirectURI = "com.example.app:/callback" },
+		},
+		{
+			name:   "private-use scheme without domain",
+			modify: func(cfg *Config) { cfg.RedirectURI = "myapp:/callback" },
+			want:   "reverse domain name",
+		},
+		{
+			name:    "auth style none with secret",
+			modify:  func(cfg *Config) { cfg.AuthStyle = AuthStyleNone },
+			wantErr: ErrConfigPublicClientSecret,
+		},
+		{
+			name: "auth style none without secret",
+			modify: func(cfg *Config) {
+				cfg.AuthStyle = AuthStyleNone
+				cfg.ClientSecret = ""
+			},
+		},
+		{
+			name:    "confidential client without secret",
+			modify:  func(cfg *Config) { cfg.ClientSecret = "" },
+			wantErr: ErrConfigClientSecretEmpty,
+		},
+		{
+			name:   "unknown auth style",
+			modify: func(cfg *Config) { cfg.AuthStyle = "private_key_jwt" },
+			want:   "auth style(private_key_jwt) is unknown",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := validTestConfig()
+			c.modify(cfg)
+
+			err := cfg.Validate()
+			switch {
+			case c.want == "" && c.wantErr == nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			case c.wantErr != nil:
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("err = %v, want %s", err, c.wantErr)
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), c.want) {
+					t.Fatalf("err = %v, want %s", err, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigValidateReportsAll(t *testing.T) {
+	err := (&Config{AuthStyle: AuthStyleNone, ClientSecret: "secret"}).Validate()
+
+	for _, want := range []error{
+		ErrConfigAuthURLEmpty,
+		ErrConfigTokenURLEmpty,
+		ErrConfigUserInfoURLEmpty,
+		ErrConfigRedirectURIEmpty,
+		ErrConfigClientIDEmpty,
+		ErrConfigPublicClientSecret,
+	} {
+		if !errors.Is(err, want) {
+			t.Errorf("%s is not reported", want)
+		}
+	}
+}
+
+type testError struct {
+	code string
+}
+
+func (e *testError) Error() string {
+	return e.code
+}
+
+func TestErrors(t *testing.T) {
+	errs := Errors{}
+	errs.Add(nil)
+	if errs.Err() != nil {
+		t.Fatal("empty errors is not nil")
+	}
+
+	errs.Add(ErrConfigClientIDEmpty)
+	errs.Add(Errors{ErrConfigTokenURLEmpty, &testError{code: "nested"}})
+	if len(errs) != 3 {
+		t.Fatalf("nested errors are not flattened: %v", errs)
+	}
+
+	if !errs.Is(ErrConfigTokenURLEmpty) || errs.Is(ErrConfigAuthURLEmpty) {
+		t.Error("Errors.Is does not match the errors")
+	}
+
+	var target *testError
+	if !errs.As(&target) || target.code != "nested" {
+		t.Error("Errors.As does not find the error")
+	}
+
+	if err := errs.Err(); !errors.Is(err, ErrConfigClientIDEmpty) {
+		t.Error("errors.Is does not look through Errors")
+	}
+
+	if errs.Error() != "oauth2: config client id is empty; oauth2: config token url is empty; nested" {
+		t.Errorf("unexpected message %s", errs.Error())
+	}
+}
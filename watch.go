@@ -0,0 +1,137 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-zoox/logger"
+)
+
+// DefaultWatchInterval is the default interval of Watcher polling the config source.
+const DefaultWatchInterval = 30 * time.Second
+
+// ConfigSource loads the provider configs keyed by provider name,
+// the configs may only hold the credentials of the registered providers.
+type ConfigSource interface {
+	Load() (map[string]*Config, error)
+}
+
+// ConfigSourceFunc is a ConfigSource of a user-supplied callback.
+type ConfigSourceFunc func() (map[string]*Config, error)
+
+// Load loads the provider configs.
+func (f ConfigSourceFunc) Load() (map[string]*Config, error) {
+	return f()
+}
+
+// Watcher polls the config source and swaps the provider configs of the registry,
+// the live clients are updated in place, see Registry.Replace.
+type Watcher struct {
+	Source ConfigSource
+	// Registry is the registry to update, default: DefaultRegistry.
+	Registry *Registry
+	Tenant   string
+	// Interval is the polling interval, default: DefaultWatchInterval.
+	Interval time.Duration
+	// OnError is called with the reload errors in Run, default: log the errors.
+	OnError func(err error)
+}
+
+// Reload loads the source and replaces the provider configs,
+// the valid providers are applied even if others fail.
+func (w *Watcher) Reload() error {
+	if w.Source == nil {
+		return fmt.Errorf("oauth2: watcher source is nil")
+	}
+
+	configs, err := w.Source.Load()
+	if err != nil {
+		return err
+	}
+
+	providers := make([]string, 0, len(configs))
+	for provider := range configs {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	errs := Errors{}
+	for _, provider := range providers {
+		config, err := w.resolve(provider, configs[provider])
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+
+		errs.Add(w.registry().Replace(w.Tenant, provider, config))
+	}
+
+	return errs.Err()
+}
+
+// Run reloads immediately and then every interval until the context is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Reload(); err != nil {
+			w.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) registry() *Registry {
+	if w.Registry != nil {
+		return w.Registry
+	}
+
+	return DefaultRegistry
+}
+
+func (w *Watcher) onError(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+		return
+	}
+
+	logger.Errorf("[oauth2][watcher] failed to reload: %s", err)
+}
+
+// resolve completes the config of the provider:
+// the registered provider factory builds the endpoints, such as from BaseURL,
+// otherwise the credentials are merged into the current config if the source has no endpoints.
+func (w *Watcher) resolve(provider string, cfg *Config) (*Config, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("oauth2: provider(%s) config is nil", provider)
+	}
+
+	if _, _, err := LookupProvider(provider); err == nil {
+		client, err := CreateProvider(provider, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Config(), nil
+	}
+
+	if current, err := w.registry().Get(w.Tenant, provider); err == nil && cfg.AuthURL == "" {
+		config := MergeCredentials(current, cfg)
+		return &config, nil
+	}
+
+	return cfg, nil
+}